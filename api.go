@@ -3,12 +3,17 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/gorilla/mux"
+	"github.com/jatin297/retoenfa/dfa"
 	"github.com/jatin297/retoenfa/dto"
+	"github.com/jatin297/retoenfa/enfa"
 	. "github.com/jatin297/retoenfa/metrics"
 	redis2 "github.com/jatin297/retoenfa/redis"
+	"github.com/jatin297/retoenfa/regex/ast"
+	"github.com/jatin297/retoenfa/render"
 	"github.com/jatin297/retoenfa/retoenfa"
 	user2 "github.com/jatin297/retoenfa/user"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -16,6 +21,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -39,6 +45,12 @@ type errorAPI struct {
 	Error string `json:"error"`
 }
 
+type parseErrorAPI struct {
+	Error    string `json:"error"`
+	Offset   int    `json:"offset"`
+	Expected string `json:"expected"`
+}
+
 func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any, start time.Time) error {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(status)
@@ -46,6 +58,93 @@ func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any, start
 	return json.NewEncoder(w).Encode(v)
 }
 
+// writeRendered answers with a renderer's output, offering its filename for download if it has one.
+func writeRendered(w http.ResponseWriter, r *http.Request, status int, renderer render.Renderer, result render.Result, start time.Time) error {
+	w.Header().Set("Content-Type", renderer.ContentType())
+	w.Header().Add("Vary", "Accept")
+	if renderer.Filename != "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", renderer.Filename))
+	}
+	w.WriteHeader(status)
+	RecordMetricForHttp(r.Method, r.RequestURI, status, start)
+	return renderer.Render(w, result)
+}
+
+// outputRenderer picks the renderer named by ?format, or else the one negotiated from the
+// Accept header. The status to answer with accompanies any error.
+func outputRenderer(r *http.Request) (render.Renderer, int, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		renderer, ok := render.Lookup(format)
+		if !ok {
+			return render.Renderer{}, http.StatusBadRequest, fmt.Errorf("unknown format: %s, expected one of %s", format, strings.Join(render.Formats(), ", "))
+		}
+		return renderer, http.StatusOK, nil
+	}
+	renderer, ok := render.Negotiate(r.Header.Get("Accept"))
+	if !ok {
+		return render.Renderer{}, http.StatusNotAcceptable, fmt.Errorf("no acceptable format, expected one of %s", strings.Join(render.Formats(), ", "))
+	}
+	return renderer, http.StatusOK, nil
+}
+
+// validAutomatonKind accepts the ?automaton values /convert understands: enfa (default), nfa
+// without epsilon moves, dfa from subset construction and its minimal-dfa.
+func validAutomatonKind(kind string) bool {
+	switch kind {
+	case "", "enfa", "nfa", "dfa", "minimal-dfa":
+		return true
+	}
+	return false
+}
+
+func selectAutomaton(trans *retoenfa.ReToeNFA, kind string) (render.Automaton, error) {
+	switch kind {
+	case "nfa":
+		nfa, err := trans.GetNFA()
+		if err != nil {
+			return nil, err
+		}
+		return nfa, nil
+	case "dfa":
+		return dfa.FromENFA(trans.GetEpsNFA())
+	case "minimal-dfa":
+		complete, err := dfa.FromENFA(trans.GetEpsNFA())
+		if err != nil {
+			return nil, err
+		}
+		minimal, _ := complete.Minimize()
+		return minimal, nil
+	}
+	return trans.GetEpsNFA(), nil
+}
+
+// inputErrorStatus is the status writeInputError answers the error with.
+func inputErrorStatus(err error) int {
+	var limitErr *dto.StateLimitError
+	if errors.As(err, &limitErr) {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
+
+// writeInputError answers a malformed regular expression with 400 and the offending position,
+// and an automaton or expression too large to build with 422. Any other error is handed back to the caller.
+func writeInputError(w http.ResponseWriter, r *http.Request, err error, start time.Time) error {
+	var limitErr *dto.StateLimitError
+	if errors.As(err, &limitErr) {
+		return writeJSON(w, r, http.StatusUnprocessableEntity, errorAPI{Error: limitErr.Error()}, start)
+	}
+	var parseErr *retoenfa.ParseError
+	if !errors.As(err, &parseErr) {
+		return err
+	}
+	return writeJSON(w, r, http.StatusBadRequest, parseErrorAPI{
+		Error:    fmt.Sprintf("invalid regular expression, err: %s", parseErr.Error()),
+		Offset:   parseErr.Offset,
+		Expected: parseErr.Expected,
+	}, start)
+}
+
 func withJWTAuth(handlerFunc http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		tokenString := r.Header.Get("Authorization")
@@ -60,6 +159,15 @@ func withJWTAuth(handlerFunc http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// withPathLabel labels the request's metrics with r.URL.Path, so query strings such as ?format=
+// and ?automaton= do not each open a new series. Handlers may still set a fixed label.
+func withPathLabel(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.RequestURI = r.URL.Path
+		next.ServeHTTP(w, r)
+	})
+}
+
 func makeHTTPHandleFunc(f funcAPI) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if err := f(w, r); err != nil {
@@ -245,29 +353,260 @@ func (s *APIService) convertToENFA(w http.ResponseWriter, r *http.Request) error
 
 	if err := json.NewDecoder(r.Body).Decode(&re); err != nil {
 		RecordMetrics(r.Method, r.RequestURI, http.StatusBadRequest, start, re, eNFA)
-		return writeJSON(w, r, http.StatusBadRequest, fmt.Errorf("invalid request body, err: %s", err.Error), start)
+		return writeJSON(w, r, http.StatusBadRequest, errorAPI{
+			Error: fmt.Sprintf("invalid request body, err: %s", err.Error()),
+		}, start)
+	}
+
+	mode, err := retoenfa.ParseMode(re.Dialect)
+	if err != nil {
+		RecordMetrics(r.Method, r.RequestURI, http.StatusBadRequest, start, re, eNFA)
+		return writeJSON(w, r, http.StatusBadRequest, errorAPI{Error: err.Error()}, start)
+	}
+
+	construction, err := retoenfa.ParseConstruction(re.Construction)
+	if err != nil {
+		RecordMetrics(r.Method, r.RequestURI, http.StatusBadRequest, start, re, eNFA)
+		return writeJSON(w, r, http.StatusBadRequest, errorAPI{Error: err.Error()}, start)
+	}
+
+	kind := r.URL.Query().Get("automaton")
+	if !validAutomatonKind(kind) {
+		RecordMetrics(r.Method, r.RequestURI, http.StatusBadRequest, start, re, eNFA)
+		return writeJSON(w, r, http.StatusBadRequest, errorAPI{Error: fmt.Sprintf("unknown automaton: %s", kind)}, start)
+	}
+	renderer, status, err := outputRenderer(r)
+	if err != nil {
+		RecordMetrics(r.Method, r.RequestURI, status, start, re, eNFA)
+		return writeJSON(w, r, status, errorAPI{Error: err.Error()}, start)
 	}
 
 	trans := retoenfa.NewReToeNFA(re.RE)
-	trans.StartParse()
-	enfa := trans.GetEpsNFA()
-	transitionTable := enfa.GenerateFormattedTransitionTable()
-	eNFA.TransitionTableSize = len(transitionTable)
+	trans.SetMode(mode)
+	trans.SetConstruction(construction)
+	trans.SetSimplify(re.Simplify)
+	if re.Alphabet != "" {
+		trans.SetAlphabet(dto.SplitSymbols(re.Alphabet))
+	}
+	if err := trans.StartParse(); err != nil {
+		RecordMetrics(r.Method, r.RequestURI, inputErrorStatus(err), start, re, eNFA)
+		return writeInputError(w, r, err, start)
+	}
+	automaton, err := selectAutomaton(trans, kind)
+	if err != nil {
+		RecordMetrics(r.Method, r.RequestURI, http.StatusUnprocessableEntity, start, re, eNFA)
+		return writeInputError(w, r, err, start)
+	}
+	transitionTable := automaton.GenerateFormattedTransitionTable()
+	eNFA.TransitionTableSize = len(transitionTable.Rows)
 
 	RecordMetrics(r.Method, r.RequestURI, http.StatusOK, start, re, eNFA)
 
 	TransitionTable.TransitionTable = transitionTable
+	if simplified, steps := trans.Simplified(); simplified != nil {
+		TransitionTable.Simplified = simplified.String()
+		for _, step := range steps {
+			TransitionTable.SimplificationSteps = append(TransitionTable.SimplificationSteps, dto.SimplificationStep{
+				Rule:   step.Rule,
+				Before: ast.Print(step.Before, mode),
+				After:  ast.Print(step.After, mode),
+			})
+		}
+	}
+
+	return writeRendered(w, r, http.StatusOK, renderer, render.Result{Automaton: automaton, Body: TransitionTable}, start)
+}
+
+func (s *APIService) convertToRegex(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != "POST" {
+		return fmt.Errorf("invalid api method")
+	}
+	var request dto.ToRegexRequest
+
+	start := time.Now()
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return writeJSON(w, r, http.StatusBadRequest, errorAPI{
+			Error: fmt.Sprintf("invalid request body, err: %s", err.Error()),
+		}, start)
+	}
+
+	r.RequestURI = "/to-regex"
+	mode, err := retoenfa.ParseMode(request.Dialect)
+	if err != nil {
+		return writeJSON(w, r, http.StatusBadRequest, errorAPI{Error: err.Error()}, start)
+	}
+	order, err := retoenfa.ParseEliminationOrder(request.Order)
+	if err != nil {
+		return writeJSON(w, r, http.StatusBadRequest, errorAPI{Error: err.Error()}, start)
+	}
+
+	automaton, err := enfa.FromTransitionTable(request.TransitionTable)
+	if err != nil {
+		return writeJSON(w, r, http.StatusBadRequest, errorAPI{
+			Error: fmt.Sprintf("invalid transition table, err: %s", err.Error()),
+		}, start)
+	}
+
+	expression, err := retoenfa.ToRegex(automaton, order)
+	if err != nil {
+		return writeInputError(w, r, err, start)
+	}
+
+	response := dto.ToRegexResponse{
+		RE:      ast.Print(expression, mode),
+		Dialect: mode.String(),
+	}
+	return writeJSON(w, r, http.StatusOK, response, start)
+}
+
+func (s *APIService) validateAutomaton(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != "POST" {
+		return fmt.Errorf("invalid api method")
+	}
+	var automaton enfa.ENFA
 
-	return writeJSON(w, r, http.StatusOK, TransitionTable, start)
+	start := time.Now()
+
+	r.RequestURI = "/automaton/validate"
+	if err := json.NewDecoder(r.Body).Decode(&automaton); err != nil {
+		return writeJSON(w, r, http.StatusBadRequest, dto.AutomatonValidation{
+			Error: fmt.Sprintf("invalid automaton, err: %s", err.Error()),
+		}, start)
+	}
+
+	table := automaton.GenerateFormattedTransitionTable()
+	return writeJSON(w, r, http.StatusOK, dto.AutomatonValidation{
+		Valid:           true,
+		States:          len(automaton.States()),
+		Transitions:     automaton.TransitionCount(),
+		Deterministic:   automaton.IsDeterministic(),
+		TransitionTable: &table,
+	}, start)
+}
+
+// importJFLAP reads a .jff document from the request body and answers with the automaton in
+// the JSON schema of enfa.ENFA.
+func (s *APIService) importJFLAP(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != "POST" {
+		return fmt.Errorf("invalid api method")
+	}
+
+	start := time.Now()
+
+	r.RequestURI = "/automaton/import/jflap"
+	automaton, err := enfa.ImportJFLAP(r.Body)
+	if err != nil {
+		return writeJSON(w, r, http.StatusBadRequest, errorAPI{
+			Error: fmt.Sprintf("invalid jflap file, err: %s", err.Error()),
+		}, start)
+	}
+	return writeJSON(w, r, http.StatusOK, automaton, start)
+}
+
+func (s *APIService) minimizeDFA(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != "POST" {
+		return fmt.Errorf("invalid api method")
+	}
+	var re dto.RegularExpression
+	var eNFA dto.ENFAResponse
+	var minimized dto.MinimizedDFA
+
+	start := time.Now()
+
+	if err := json.NewDecoder(r.Body).Decode(&re); err != nil {
+		RecordMetrics(r.Method, r.RequestURI, http.StatusBadRequest, start, re, eNFA)
+		return writeJSON(w, r, http.StatusBadRequest, errorAPI{
+			Error: fmt.Sprintf("invalid request body, err: %s", err.Error()),
+		}, start)
+	}
+
+	mode, err := retoenfa.ParseMode(re.Dialect)
+	if err != nil {
+		RecordMetrics(r.Method, r.RequestURI, http.StatusBadRequest, start, re, eNFA)
+		return writeJSON(w, r, http.StatusBadRequest, errorAPI{Error: err.Error()}, start)
+	}
+
+	trans := retoenfa.NewReToeNFA(re.RE)
+	trans.SetMode(mode)
+	if re.Alphabet != "" {
+		trans.SetAlphabet(dto.SplitSymbols(re.Alphabet))
+	}
+	if err := trans.StartParse(); err != nil {
+		RecordMetrics(r.Method, r.RequestURI, inputErrorStatus(err), start, re, eNFA)
+		return writeInputError(w, r, err, start)
+	}
+	complete, err := dfa.FromENFA(trans.GetEpsNFA())
+	if err != nil {
+		RecordMetrics(r.Method, r.RequestURI, http.StatusUnprocessableEntity, start, re, eNFA)
+		return writeInputError(w, r, err, start)
+	}
+	minimal, partition := complete.Minimize()
+	transitionTable := minimal.GenerateFormattedTransitionTable()
+	eNFA.TransitionTableSize = len(transitionTable.Rows)
+
+	RecordMetrics(r.Method, r.RequestURI, http.StatusOK, start, re, eNFA)
+
+	minimized.TransitionTable = transitionTable
+	minimized.EquivalenceClasses = make(map[string][]int)
+	for index, state := range minimal.States() {
+		minimized.EquivalenceClasses[strconv.Itoa(state)] = partition[index]
+	}
+
+	return writeJSON(w, r, http.StatusOK, minimized, start)
+}
+
+func (s *APIService) checkEquivalence(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != "POST" {
+		return fmt.Errorf("invalid api method")
+	}
+	var request dto.EquivalenceRequest
+	var response dto.EquivalenceResponse
+
+	start := time.Now()
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return writeJSON(w, r, http.StatusBadRequest, errorAPI{
+			Error: fmt.Sprintf("invalid request body, err: %s", err.Error()),
+		}, start)
+	}
+
+	r.RequestURI = "/equivalent"
+	mode, err := retoenfa.ParseMode(request.Dialect)
+	if err != nil {
+		return writeJSON(w, r, http.StatusBadRequest, errorAPI{Error: err.Error()}, start)
+	}
+
+	equivalent, witness, acceptedByFirst, err := retoenfa.Equivalent(request.First, request.Second, mode)
+	if err != nil {
+		return writeInputError(w, r, err, start)
+	}
+	response.Equivalent = equivalent
+	if !equivalent {
+		counterexample := dto.JoinSymbols(witness)
+		response.Counterexample = &counterexample
+		response.AcceptedBy = "second"
+		if acceptedByFirst {
+			response.AcceptedBy = "first"
+		}
+	}
+
+	return writeJSON(w, r, http.StatusOK, response, start)
 }
 
 func (s *APIService) Run() {
 	router := mux.NewRouter()
+	router.Use(withPathLabel)
 
 	router.HandleFunc("/user/login", makeHTTPHandleFunc(s.handleLogin))
 	router.HandleFunc("/user/{id}", withJWTAuth(makeHTTPHandleFunc(s.handleGetUserByID)))
 	router.HandleFunc("/user", makeHTTPHandleFunc(s.handleUserAPI))
 	router.HandleFunc("/convert", withJWTAuth(makeHTTPHandleFunc(s.convertToENFA)))
+	router.HandleFunc("/equivalent", withJWTAuth(makeHTTPHandleFunc(s.checkEquivalence)))
+	router.HandleFunc("/minimize", withJWTAuth(makeHTTPHandleFunc(s.minimizeDFA)))
+	router.HandleFunc("/to-regex", withJWTAuth(makeHTTPHandleFunc(s.convertToRegex)))
+	router.HandleFunc("/automaton/validate", withJWTAuth(makeHTTPHandleFunc(s.validateAutomaton)))
+	router.HandleFunc("/automaton/import/jflap", withJWTAuth(makeHTTPHandleFunc(s.importJFLAP)))
 	router.Handle("/metrics", promhttp.Handler())
 
 	log.Println("api server running on port: ", s.listenAddr)
@@ -0,0 +1,179 @@
+package dfa
+
+import (
+	"fmt"
+	. "github.com/jatin297/retoenfa/dto"
+	"github.com/jatin297/retoenfa/enfa"
+	"sort"
+	"strings"
+)
+
+type DFA struct {
+	initialState int
+	states       []int
+	finalStates  StateSet
+	transitions  map[TransitionKey]int
+	inputSymbols []Symbol
+	nfaStates    map[int][]int
+}
+
+// maxSubsetStates bounds the DFA built by subset construction, which can be exponentially
+// larger than the ENFA.
+const maxSubsetStates = 4096
+
+// FromENFA builds a complete DFA from the given ENFA using subset construction.
+// DFA states are numbered in discovery order, the empty subset becomes DeadState.
+// A *StateLimitError is returned when the DFA would exceed maxSubsetStates states.
+func FromENFA(e *enfa.ENFA) (*DFA, error) {
+	d := &DFA{
+		finalStates:  make(StateSet),
+		transitions:  make(map[TransitionKey]int),
+		inputSymbols: e.InputSymbols(),
+		nfaStates:    make(map[int][]int),
+	}
+
+	subsetIDs := make(map[string]int)
+	nextID := 0
+	var queue []int
+
+	// lookup returns the DFA state for a subset, registering it on first sight
+	lookup := func(subset []int) int {
+		key := subsetKey(subset)
+		if id, found := subsetIDs[key]; found {
+			return id
+		}
+
+		id := DeadState
+		if len(subset) > 0 {
+			id = nextID
+			nextID++
+		}
+		subsetIDs[key] = id
+		d.states = append(d.states, id)
+		d.nfaStates[id] = subset
+		for _, state := range subset {
+			if e.IsFinalState(state) {
+				d.finalStates[id] = true
+				break
+			}
+		}
+		queue = append(queue, id)
+		return id
+	}
+
+	d.initialState = lookup(e.EpsilonClosure([]int{e.InitialState()}))
+
+	for len(queue) > 0 {
+		if len(d.states) > maxSubsetStates {
+			return nil, &StateLimitError{Construction: "subset construction", Limit: maxSubsetStates}
+		}
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, symbol := range d.inputSymbols {
+			target := lookup(e.EpsilonClosure(move(e, d.nfaStates[current], symbol)))
+			d.transitions[TransitionKey{SourceState: current, InputSymbol: symbol}] = target
+		}
+	}
+	return d, nil
+}
+
+// move returns the sorted set of states reachable from the given states on one input symbol.
+func move(e *enfa.ENFA, states []int, symbol Symbol) []int {
+	reached := make(StateSet)
+	for _, state := range states {
+		for _, dest := range e.NextStates(state, symbol) {
+			reached[dest] = true
+		}
+	}
+	return sortedStates(reached)
+}
+
+func sortedStates(set StateSet) []int {
+	var stateList []int
+	for state := range set {
+		stateList = append(stateList, state)
+	}
+	sort.Ints(stateList)
+	return stateList
+}
+
+func subsetKey(subset []int) string {
+	parts := make([]string, len(subset))
+	for i, state := range subset {
+		parts[i] = fmt.Sprintf("%d", state)
+	}
+	return strings.Join(parts, ",")
+}
+
+// InitialState returns the start state of the DFA.
+func (d *DFA) InitialState() int {
+	return d.initialState
+}
+
+// States returns the DFA states in discovery order.
+func (d *DFA) States() []int {
+	return append([]int(nil), d.states...)
+}
+
+// IsFinalState reports whether the given state is accepting.
+func (d *DFA) IsFinalState(state int) bool {
+	return d.finalStates[state]
+}
+
+// InputSymbols returns the sorted input alphabet of the DFA.
+func (d *DFA) InputSymbols() []Symbol {
+	return append([]Symbol(nil), d.inputSymbols...)
+}
+
+// NextState returns the target of the transition on symbol, if the symbol is part of the alphabet.
+func (d *DFA) NextState(state int, symbol Symbol) (int, bool) {
+	target, exists := d.transitions[TransitionKey{SourceState: state, InputSymbol: symbol}]
+	return target, exists
+}
+
+// NFAStates returns the set of ENFA states the given DFA state was built from.
+func (d *DFA) NFAStates(state int) []int {
+	return append([]int(nil), d.nfaStates[state]...)
+}
+
+// ValidateInputSequence determines whether the DFA accepts a given sequence of input symbols.
+func (d *DFA) ValidateInputSequence(inputs []Symbol) bool {
+	current := d.initialState
+	for _, inputSymbol := range inputs {
+		next, exists := d.NextState(current, inputSymbol)
+		if !exists {
+			return false
+		}
+		current = next
+	}
+	return d.IsFinalState(current)
+}
+
+// GenerateFormattedTransitionTable creates a structured view of the transition table with states
+// ascending, so DeadState comes first, and the NFA states each DFA state stands for.
+func (d *DFA) GenerateFormattedTransitionTable() Table {
+	table := Table{InitialState: d.initialState, Symbols: make([]string, len(d.inputSymbols)), Rows: []TableRow{}}
+	for i, symbol := range d.inputSymbols {
+		table.Symbols[i] = symbol.String()
+	}
+
+	states := d.States()
+	sort.Ints(states)
+	for _, state := range states {
+		row := TableRow{
+			State:     state,
+			Final:     d.IsFinalState(state),
+			NFAStates: append([]int{}, d.nfaStates[state]...),
+			Next:      make([][]int, len(d.inputSymbols)),
+		}
+		for i, symbol := range d.inputSymbols {
+			row.Next[i] = []int{}
+			if target, exists := d.NextState(state, symbol); exists {
+				row.Next[i] = []int{target}
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
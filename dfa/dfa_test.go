@@ -0,0 +1,229 @@
+package dfa
+
+import (
+	"errors"
+	. "github.com/jatin297/retoenfa/dto"
+	"github.com/jatin297/retoenfa/enfa"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// dragonBookENFA builds the Thompson automaton for (a+b)*.a.b.b from the dragon book.
+func dragonBookENFA() *enfa.ENFA {
+	nfa := enfa.CreateENFA(0, false)
+	for state := 1; state <= 10; state++ {
+		nfa.InsertState(state, state == 10)
+	}
+
+	nfa.DefineTransition(0, "", 1, 7)
+	nfa.DefineTransition(1, "", 2, 4)
+	nfa.DefineTransition(2, "a", 3)
+	nfa.DefineTransition(4, "b", 5)
+	nfa.DefineTransition(3, "", 6)
+	nfa.DefineTransition(5, "", 6)
+	nfa.DefineTransition(6, "", 1, 7)
+	nfa.DefineTransition(7, "a", 8)
+	nfa.DefineTransition(8, "b", 9)
+	nfa.DefineTransition(9, "b", 10)
+	return nfa
+}
+
+func mustFromENFA(t *testing.T, e *enfa.ENFA) *DFA {
+	t.Helper()
+	d, err := FromENFA(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestFromENFASubsetConstruction(t *testing.T) {
+	d := mustFromENFA(t, dragonBookENFA())
+
+	if got := len(d.States()); got != 5 {
+		t.Errorf("Expect 5 DFA states, but get %d", got)
+	}
+
+	if got := d.NFAStates(d.InitialState()); !reflect.DeepEqual(got, []int{0, 1, 2, 4, 7}) {
+		t.Errorf("Expect initial subset {0,1,2,4,7}, but get %v", got)
+	}
+
+	if !reflect.DeepEqual(d.InputSymbols(), []Symbol{"a", "b"}) {
+		t.Errorf("Expect alphabet [a b], but get %v", d.InputSymbols())
+	}
+
+	accepted := []string{"abb", "aabb", "babb", "ababb", "bbbabb"}
+	for _, input := range accepted {
+		if !d.ValidateInputSequence(SplitSymbols(input)) {
+			t.Errorf("Expect %q to be accepted", input)
+		}
+	}
+
+	rejected := []string{"", "a", "ab", "abba", "abbb", "abc"}
+	for _, input := range rejected {
+		if d.ValidateInputSequence(SplitSymbols(input)) {
+			t.Errorf("Expect %q to be rejected", input)
+		}
+	}
+}
+
+func TestFromENFADeadState(t *testing.T) {
+	nfa := enfa.CreateENFA(0, false)
+	nfa.InsertState(1, true)
+	nfa.DefineTransition(0, "a", 1)
+	nfa.DefineTransition(0, "b", 0)
+
+	d := mustFromENFA(t, nfa)
+
+	target, exists := d.NextState(d.InitialState(), "a")
+	if !exists {
+		t.Fatalf("Expect transition on a from initial state")
+	}
+
+	dead, _ := d.NextState(target, "a")
+	if dead != -1 || len(d.NFAStates(dead)) != 0 {
+		t.Errorf("Expect empty subset to map to the dead state, but get %d", dead)
+	}
+
+	table := d.GenerateFormattedTransitionTable()
+	if len(table.Rows) != 3 {
+		t.Errorf("Expect 3 rows, but get %d", len(table.Rows))
+	}
+	if table.Rows[0].State != DeadState || table.Rows[1].State != 0 {
+		t.Errorf("Expect rows in ascending state order, but get %v", table.Rows)
+	}
+	if !reflect.DeepEqual(table.Rows[1].NFAStates, []int{0}) {
+		t.Errorf("Expect initial row to map to {0}, but get %v", table.Rows[1].NFAStates)
+	}
+
+	// Loading the table back drops the dead state without changing the language
+	loaded, err := enfa.FromTransitionTable(table)
+	if err != nil || len(loaded.States()) != 2 {
+		t.Fatalf("Expect the table to load without its dead state, but get %v (err=%v)", loaded, err)
+	}
+	if !loaded.ValidateInputSequence([]Symbol{"a"}) {
+		t.Errorf("Expect the loaded automaton to accept a")
+	}
+}
+
+func TestMinimizeMergesEquivalentStates(t *testing.T) {
+	d := mustFromENFA(t, dragonBookENFA())
+	minimal, partition := d.Minimize()
+
+	if got := len(minimal.States()); got != 4 {
+		t.Errorf("Expect 4 minimal states, but get %d", got)
+	}
+	if len(partition) != len(minimal.States()) {
+		t.Fatalf("Expect one equivalence class per state, but get %d", len(partition))
+	}
+
+	merged := 0
+	for _, class := range partition {
+		if len(class) > 1 {
+			merged++
+			if !reflect.DeepEqual(class, []int{0, 2}) {
+				t.Errorf("Expect DFA states 0 and 2 to be merged, but get %v", class)
+			}
+		}
+	}
+	if merged != 1 {
+		t.Errorf("Expect exactly one merged class, but get %d", merged)
+	}
+
+	for _, input := range []string{"", "a", "abb", "babb", "abab", "aababb"} {
+		if d.ValidateInputSequence(SplitSymbols(input)) != minimal.ValidateInputSequence(SplitSymbols(input)) {
+			t.Errorf("Expect minimal DFA to agree with original on %q", input)
+		}
+	}
+}
+
+func TestMinimizeKeepsDeadState(t *testing.T) {
+	nfa := enfa.CreateENFA(0, false)
+	nfa.InsertState(1, true)
+	nfa.InsertState(2, true)
+	nfa.DefineTransition(0, "a", 1, 2)
+	nfa.DefineTransition(1, "b", 2)
+	nfa.DefineTransition(2, "b", 1)
+
+	minimal, partition := mustFromENFA(t, nfa).Minimize()
+
+	// ab* over {a, b}: start, accepting loop and dead state
+	if got := len(minimal.States()); got != 3 {
+		t.Errorf("Expect 3 minimal states, but get %d", got)
+	}
+	if len(partition) != 3 {
+		t.Errorf("Expect 3 equivalence classes, but get %d", len(partition))
+	}
+
+	for _, state := range minimal.States() {
+		for _, symbol := range minimal.InputSymbols() {
+			if _, exists := minimal.NextState(state, symbol); !exists {
+				t.Errorf("Expect minimal DFA to be complete, missing %d on %s", state, symbol)
+			}
+		}
+	}
+
+	if !minimal.ValidateInputSequence(SplitSymbols("abbb")) || minimal.ValidateInputSequence(SplitSymbols("aba")) {
+		t.Errorf("Expect minimal DFA to accept a.b*")
+	}
+}
+
+func TestExportDOT(t *testing.T) {
+	minimal, _ := mustFromENFA(t, dragonBookENFA()).Minimize()
+	var out strings.Builder
+	if err := minimal.ExportDOT(&out); err != nil {
+		t.Fatal(err)
+	}
+	dot := out.String()
+	for _, expected := range []string{"digraph dfa {", `__start -> "0";`, "shape=doublecircle", `[label="a"]`, `[label="b"]`} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("Expect %q in\n%s", expected, dot)
+		}
+	}
+	if strings.Count(dot, "->") != 1+2*len(minimal.States()) {
+		t.Errorf("Expect one edge per state and symbol plus the start arrow, but get\n%s", dot)
+	}
+}
+
+func TestExportJFLAP(t *testing.T) {
+	nfa := enfa.CreateENFA(0, false)
+	nfa.InsertState(1, true)
+	nfa.DefineTransition(0, "a", 1)
+	d := mustFromENFA(t, nfa)
+
+	var out strings.Builder
+	if err := d.ExportJFLAP(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `name="∅"`) {
+		t.Errorf("Expect the dead state to be named ∅ in\n%s", out.String())
+	}
+
+	imported, err := enfa.ImportJFLAP(strings.NewReader(out.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if equivalent, witness, _, _ := enfa.Equivalent(nfa, imported); !equivalent {
+		t.Errorf("Expect the exported DFA to be equivalent, but get witness %v", witness)
+	}
+}
+
+func TestFromENFAStateLimit(t *testing.T) {
+	// (a+b)*.a.(a+b)^13 needs a DFA state per suffix of 14 symbols
+	nfa := enfa.CreateENFA(0, false)
+	nfa.DefineTransition(0, "a", 0, 1)
+	nfa.DefineTransition(0, "b", 0)
+	for state := 1; state <= 14; state++ {
+		nfa.InsertState(state, state == 14)
+		if state > 1 {
+			nfa.DefineTransition(state-1, "a", state)
+			nfa.DefineTransition(state-1, "b", state)
+		}
+	}
+
+	var limitErr *StateLimitError
+	if _, err := FromENFA(nfa); !errors.As(err, &limitErr) || limitErr.Limit != maxSubsetStates {
+		t.Errorf("Expect a StateLimitError, but get %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package dfa
+
+import (
+	"fmt"
+	"github.com/jatin297/retoenfa/diagram"
+	. "github.com/jatin297/retoenfa/dto"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ExportSVG draws the DFA as an SVG state diagram, DeadState labelled ∅.
+func (d *DFA) ExportSVG(w io.Writer) error {
+	return diagram.WriteSVG(w, d.Diagram())
+}
+
+// ExportMermaid writes the DFA as a Mermaid stateDiagram-v2.
+func (d *DFA) ExportMermaid(w io.Writer) error {
+	return diagram.WriteMermaid(w, d.Diagram())
+}
+
+// ExportTikZ writes the DFA as a TikZ picture for the automata library.
+func (d *DFA) ExportTikZ(w io.Writer) error {
+	return diagram.WriteTikZ(w, d.Diagram())
+}
+
+// Diagram describes the DFA for the diagram package, states in ascending order and the
+// symbols leading to the same target merged onto one edge.
+func (d *DFA) Diagram() *diagram.Diagram {
+	states := d.States()
+	sort.Ints(states)
+
+	result := &diagram.Diagram{Initial: d.initialState}
+	for _, state := range states {
+		label := fmt.Sprintf("%d", state)
+		if state == DeadState {
+			label = "∅"
+		}
+		result.States = append(result.States, diagram.State{ID: state, Label: label, Final: d.IsFinalState(state)})
+
+		var targets []int
+		labels := make(map[int][]string)
+		for _, symbol := range d.inputSymbols {
+			if target, ok := d.NextState(state, symbol); ok {
+				if labels[target] == nil {
+					targets = append(targets, target)
+				}
+				labels[target] = append(labels[target], symbol.String())
+			}
+		}
+		sort.Ints(targets)
+		for _, target := range targets {
+			result.Edges = append(result.Edges, diagram.Edge{Src: state, Dst: target, Label: strings.Join(labels[target], ",")})
+		}
+	}
+	return result
+}
@@ -0,0 +1,43 @@
+package dfa
+
+import (
+	"bufio"
+	"fmt"
+	. "github.com/jatin297/retoenfa/dto"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ExportDOT writes the DFA as a Graphviz digraph in the style of enfa.ENFA.ExportDOT. Each
+// state is labelled with the NFA states it stands for, DeadState is drawn as ∅, and the
+// symbols leading from one state to the same target share an edge.
+func (d *DFA) ExportDOT(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "digraph dfa {")
+	fmt.Fprintln(out, "\trankdir=LR;")
+	fmt.Fprintln(out, "\tnode [shape=circle];")
+	fmt.Fprintln(out, "\t__start [shape=point];")
+	fmt.Fprintf(out, "\t__start -> \"%d\";\n", d.initialState)
+
+	states := d.States()
+	sort.Ints(states)
+	for _, state := range states {
+		shape := "circle"
+		if d.IsFinalState(state) {
+			shape = "doublecircle"
+		}
+		label := fmt.Sprintf("%d\\n{%s}", state, subsetKey(d.nfaStates[state]))
+		if state == DeadState {
+			label = "∅"
+		}
+		fmt.Fprintf(out, "\t\"%d\" [shape=%s, label=\"%s\"];\n", state, shape, label)
+	}
+
+	for _, edge := range d.Diagram().Edges {
+		label := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(edge.Label)
+		fmt.Fprintf(out, "\t\"%d\" -> \"%d\" [label=\"%s\"];\n", edge.Src, edge.Dst, label)
+	}
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
@@ -0,0 +1,52 @@
+package dfa
+
+import (
+	"fmt"
+	"github.com/jatin297/retoenfa/diagram"
+	. "github.com/jatin297/retoenfa/dto"
+	"github.com/jatin297/retoenfa/jflap"
+	"io"
+	"sort"
+)
+
+// ExportJFLAP writes the DFA as a JFLAP .jff file laid out like ExportSVG. JFLAP ids cannot
+// be negative, so DeadState takes the id after the largest state and is named ∅.
+func (d *DFA) ExportJFLAP(w io.Writer) error {
+	positions := diagram.Layout(d.Diagram())
+	states := d.States()
+	sort.Ints(states)
+
+	ids := make(map[int]int)
+	deadID := 0
+	for _, state := range states {
+		ids[state] = state
+		if state >= deadID {
+			deadID = state + 1
+		}
+	}
+	ids[DeadState] = deadID
+
+	automaton := &jflap.Automaton{}
+	for _, state := range states {
+		name := fmt.Sprintf("q%d", state)
+		if state == DeadState {
+			name = "∅"
+		}
+		automaton.States = append(automaton.States, jflap.State{
+			ID:      ids[state],
+			Name:    name,
+			X:       positions[state].X,
+			Y:       positions[state].Y,
+			Initial: state == d.initialState,
+			Final:   d.IsFinalState(state),
+		})
+	}
+	for _, state := range states {
+		for _, symbol := range d.inputSymbols {
+			if target, ok := d.NextState(state, symbol); ok {
+				automaton.Transitions = append(automaton.Transitions, jflap.Transition{From: ids[state], To: ids[target], Read: string(symbol)})
+			}
+		}
+	}
+	return jflap.Write(w, automaton)
+}
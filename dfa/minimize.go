@@ -0,0 +1,266 @@
+package dfa
+
+import (
+	. "github.com/jatin297/retoenfa/dto"
+	"sort"
+)
+
+// Minimize returns the minimal complete DFA accepting the same language, computed with
+// Hopcroft's partition refinement. The returned partition lists the original states merged
+// into each state of the minimal DFA, in the same order as its States().
+func (d *DFA) Minimize() (*DFA, [][]int) {
+	complete := d.reachable().complete()
+
+	// Start from the accepting / non-accepting split, dropping empty blocks
+	var accepting, rejecting []int
+	for _, state := range complete.states {
+		if complete.finalStates[state] {
+			accepting = append(accepting, state)
+		} else {
+			rejecting = append(rejecting, state)
+		}
+	}
+
+	var partition [][]int
+	for _, block := range [][]int{accepting, rejecting} {
+		if len(block) > 0 {
+			partition = append(partition, block)
+		}
+	}
+
+	blockOf := make(map[int]int)
+	for index, block := range partition {
+		for _, state := range block {
+			blockOf[state] = index
+		}
+	}
+
+	// Every transition reversed once, so splitting looks up predecessors instead of scanning
+	inverse := make(map[TransitionKey][]int)
+	for _, state := range complete.states {
+		for _, symbol := range complete.inputSymbols {
+			target, _ := complete.NextState(state, symbol)
+			key := TransitionKey{SourceState: target, InputSymbol: symbol}
+			inverse[key] = append(inverse[key], state)
+		}
+	}
+
+	// The worklist holds indexes into partition of the blocks still to split against
+	var worklist []int
+	pending := make(map[int]bool)
+	addPending := func(index int) {
+		worklist = append(worklist, index)
+		pending[index] = true
+	}
+	if len(partition) == 2 && len(partition[1]) < len(partition[0]) {
+		addPending(1)
+	} else {
+		addPending(0)
+	}
+
+	for len(worklist) > 0 {
+		splitterIndex := worklist[0]
+		worklist = worklist[1:]
+		delete(pending, splitterIndex)
+		splitter := append([]int(nil), partition[splitterIndex]...)
+
+		for _, symbol := range complete.inputSymbols {
+			// Predecessors of the splitter on this symbol, grouped by their block
+			predecessors := make(StateSet)
+			var touched []int
+			for _, target := range splitter {
+				for _, state := range inverse[TransitionKey{SourceState: target, InputSymbol: symbol}] {
+					if !predecessors[state] {
+						predecessors[state] = true
+						touched = append(touched, blockOf[state])
+					}
+				}
+			}
+
+			split := make(map[int]bool)
+			for _, index := range touched {
+				if split[index] {
+					continue
+				}
+				split[index] = true
+
+				var inside, outside []int
+				for _, state := range partition[index] {
+					if predecessors[state] {
+						inside = append(inside, state)
+					} else {
+						outside = append(outside, state)
+					}
+				}
+				if len(outside) == 0 {
+					continue
+				}
+
+				partition[index] = inside
+				partition = append(partition, outside)
+				outsideIndex := len(partition) - 1
+				for _, state := range outside {
+					blockOf[state] = outsideIndex
+				}
+
+				if pending[index] || len(outside) <= len(inside) {
+					addPending(outsideIndex)
+				} else {
+					addPending(index)
+				}
+			}
+		}
+	}
+
+	return complete.quotient(partition)
+}
+
+// quotient builds the DFA whose states are the blocks of the partition. States are renumbered
+// in breadth-first order from the initial block; a rejecting trap block becomes DeadState.
+func (d *DFA) quotient(partition [][]int) (*DFA, [][]int) {
+	blockOf := make(map[int]int)
+	for index, block := range partition {
+		for _, state := range block {
+			blockOf[state] = index
+		}
+	}
+
+	isTrap := func(index int) bool {
+		representative := partition[index][0]
+		if d.finalStates[representative] {
+			return false
+		}
+		for _, symbol := range d.inputSymbols {
+			if target, _ := d.NextState(representative, symbol); blockOf[target] != index {
+				return false
+			}
+		}
+		return true
+	}
+
+	minimal := &DFA{
+		finalStates:  make(StateSet),
+		transitions:  make(map[TransitionKey]int),
+		inputSymbols: d.InputSymbols(),
+		nfaStates:    make(map[int][]int),
+	}
+
+	newID := make(map[int]int)
+	nextID := 0
+	var classes [][]int
+	var queue []int
+
+	lookup := func(index int) int {
+		if id, found := newID[index]; found {
+			return id
+		}
+
+		id := DeadState
+		if !isTrap(index) {
+			id = nextID
+			nextID++
+		}
+		newID[index] = id
+
+		members := append([]int(nil), partition[index]...)
+		sort.Ints(members)
+		classes = append(classes, members)
+
+		nfaStates := make(StateSet)
+		for _, member := range members {
+			for _, state := range d.nfaStates[member] {
+				nfaStates[state] = true
+			}
+		}
+		minimal.states = append(minimal.states, id)
+		minimal.nfaStates[id] = sortedStates(nfaStates)
+		if d.finalStates[members[0]] {
+			minimal.finalStates[id] = true
+		}
+		queue = append(queue, index)
+		return id
+	}
+
+	minimal.initialState = lookup(blockOf[d.initialState])
+	for len(queue) > 0 {
+		index := queue[0]
+		queue = queue[1:]
+
+		representative := partition[index][0]
+		for _, symbol := range d.inputSymbols {
+			target, _ := d.NextState(representative, symbol)
+			minimal.transitions[TransitionKey{SourceState: newID[index], InputSymbol: symbol}] = lookup(blockOf[target])
+		}
+	}
+	return minimal, classes
+}
+
+// reachable returns a copy of the DFA restricted to states reachable from the initial state.
+func (d *DFA) reachable() *DFA {
+	visited := StateSet{d.initialState: true}
+	order := []int{d.initialState}
+	for index := 0; index < len(order); index++ {
+		for _, symbol := range d.inputSymbols {
+			if target, exists := d.NextState(order[index], symbol); exists && !visited[target] {
+				visited[target] = true
+				order = append(order, target)
+			}
+		}
+	}
+
+	restricted := &DFA{
+		initialState: d.initialState,
+		states:       order,
+		finalStates:  make(StateSet),
+		transitions:  make(map[TransitionKey]int),
+		inputSymbols: d.InputSymbols(),
+		nfaStates:    make(map[int][]int),
+	}
+	for _, state := range order {
+		restricted.finalStates[state] = d.finalStates[state]
+		restricted.nfaStates[state] = d.nfaStates[state]
+		for _, symbol := range d.inputSymbols {
+			if target, exists := d.NextState(state, symbol); exists {
+				restricted.transitions[TransitionKey{SourceState: state, InputSymbol: symbol}] = target
+			}
+		}
+	}
+	return restricted
+}
+
+// complete sends every missing transition to DeadState in place, adding the state when needed.
+func (d *DFA) complete() *DFA {
+	hasDead := false
+	for _, state := range d.states {
+		if state == DeadState {
+			hasDead = true
+		}
+	}
+
+	states := append([]int(nil), d.states...)
+	for _, state := range states {
+		for _, symbol := range d.inputSymbols {
+			key := TransitionKey{SourceState: state, InputSymbol: symbol}
+			if _, exists := d.transitions[key]; exists {
+				continue
+			}
+			if !hasDead {
+				hasDead = true
+				d.states = append(d.states, DeadState)
+				for _, deadSymbol := range d.inputSymbols {
+					d.transitions[TransitionKey{SourceState: DeadState, InputSymbol: deadSymbol}] = DeadState
+				}
+			}
+			d.transitions[key] = DeadState
+		}
+	}
+	return d
+}
+
+func toSet(states []int) StateSet {
+	set := make(StateSet)
+	for _, state := range states {
+		set[state] = true
+	}
+	return set
+}
@@ -0,0 +1,30 @@
+// Package diagram lays out and draws state diagrams without external tools.
+package diagram
+
+// Diagram is a state diagram to be laid out: states, labelled edges and the initial state.
+// Edges between the same pair of states are expected to be merged into one label already.
+type Diagram struct {
+	States  []State
+	Edges   []Edge
+	Initial int
+}
+
+// State is a node of the diagram, drawn with a double border when Final.
+type State struct {
+	ID    int
+	Label string
+	Final bool
+}
+
+// Edge is a labelled arrow from Src to Dst; Src equal to Dst draws a self-loop.
+type Edge struct {
+	Src   int
+	Dst   int
+	Label string
+}
+
+// Point is a position on the canvas, y growing downwards.
+type Point struct {
+	X float64
+	Y float64
+}
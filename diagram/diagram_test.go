@@ -0,0 +1,105 @@
+package diagram
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+// sample is the DFA of (a+b)*.a.b.b with an unreachable state 4.
+func sample() *Diagram {
+	return &Diagram{
+		Initial: 0,
+		States:  []State{{ID: 0, Label: "0"}, {ID: 1, Label: "1"}, {ID: 2, Label: "2"}, {ID: 3, Label: "3", Final: true}, {ID: 4, Label: "<4>"}},
+		Edges: []Edge{
+			{Src: 0, Dst: 1, Label: "a"}, {Src: 0, Dst: 0, Label: "b"},
+			{Src: 1, Dst: 1, Label: "a"}, {Src: 1, Dst: 2, Label: "b"},
+			{Src: 2, Dst: 1, Label: "a"}, {Src: 2, Dst: 3, Label: "b"},
+			{Src: 3, Dst: 1, Label: "a"}, {Src: 3, Dst: 0, Label: "b"},
+			{Src: 4, Dst: 0, Label: "a,b"},
+		},
+	}
+}
+
+func TestLayout(t *testing.T) {
+	positions := Layout(sample())
+	if len(positions) != 5 {
+		t.Fatalf("Expect every state placed, but get %v", positions)
+	}
+	if positions[0].X != margin {
+		t.Errorf("Expect the initial state in the first layer, but get %v", positions[0])
+	}
+	for _, state := range []int{1, 2, 3} {
+		if positions[state].X != margin+float64(state)*layerGap {
+			t.Errorf("Expect state %d in layer %d, but get %v", state, state, positions[state])
+		}
+	}
+	if positions[4].X <= positions[3].X {
+		t.Errorf("Expect the unreachable state after the reachable ones, but get %v", positions[4])
+	}
+
+	seen := make(map[Point]bool)
+	for state, point := range positions {
+		if seen[point] {
+			t.Errorf("Expect distinct positions, but state %d overlaps at %v", state, point)
+		}
+		seen[point] = true
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	var out strings.Builder
+	if err := WriteSVG(&out, sample()); err != nil {
+		t.Fatal(err)
+	}
+	svg := out.String()
+
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Expect well-formed XML, but get %v in\n%s", err, svg)
+		}
+	}
+
+	for marker, count := range map[string]int{`class="state"`: 5, `class="final"`: 1, `class="start"`: 1, `class="edge"`: 9, "&lt;4&gt;": 1} {
+		if got := strings.Count(svg, marker); got != count {
+			t.Errorf("Expect %d of %s, but get %d", count, marker, got)
+		}
+	}
+	if !strings.Contains(svg, " C") {
+		t.Errorf("Expect self-loops drawn as cubic curves")
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var out strings.Builder
+	if err := WriteMermaid(&out, sample()); err != nil {
+		t.Fatal(err)
+	}
+	mermaid := out.String()
+	for _, expected := range []string{"stateDiagram-v2\n", "\t[*] --> s0\n", "\ts0 --> s0 : b\n", "\ts3 --> [*]\n", `state "#60;4#62;" as s4`} {
+		if !strings.Contains(mermaid, expected) {
+			t.Errorf("Expect %q in\n%s", expected, mermaid)
+		}
+	}
+}
+
+func TestWriteTikZ(t *testing.T) {
+	var out strings.Builder
+	if err := WriteTikZ(&out, sample()); err != nil {
+		t.Fatal(err)
+	}
+	tikz := out.String()
+	for _, expected := range []string{`\node[state, initial] (q0) at (1.50, `, `\node[state, accepting] (q3)`, "(q0) edge[loop above] node {b} ()", "(q1) edge[bend left] node {b} (q2)", "(q4) edge node {a,b} (q0);\n"} {
+		if !strings.Contains(tikz, expected) {
+			t.Errorf("Expect %q in\n%s", expected, tikz)
+		}
+	}
+
+	if escaped := LaTeXEscape(`ε,a_b\`); escaped != `$\varepsilon$,a\_b\textbackslash{}` {
+		t.Errorf("Expect LaTeX escapes, but get %s", escaped)
+	}
+}
@@ -0,0 +1,137 @@
+package diagram
+
+import "sort"
+
+// Spacing of the layered layout.
+const (
+	margin     = 90.0
+	layerGap   = 120.0
+	rowGap     = 90.0
+	orderSweep = 8
+)
+
+// Layout places the states in layers from left to right by their breadth-first distance from
+// the initial state, then orders each layer by the barycenter of its neighbours to reduce
+// edge crossings. States unreachable from the initial state follow in further layers.
+func Layout(d *Diagram) map[int]Point {
+	neighbours := make(map[int][]int)
+	for _, edge := range d.Edges {
+		if edge.Src != edge.Dst {
+			neighbours[edge.Src] = append(neighbours[edge.Src], edge.Dst)
+			neighbours[edge.Dst] = append(neighbours[edge.Dst], edge.Src)
+		}
+	}
+
+	layerOf := assignLayers(d, neighbours)
+	var layers [][]int
+	for _, state := range d.States {
+		layer := layerOf[state.ID]
+		for len(layers) <= layer {
+			layers = append(layers, nil)
+		}
+		layers[layer] = append(layers[layer], state.ID)
+	}
+
+	order := orderLayers(layers, neighbours)
+
+	tallest := 0
+	for _, layer := range layers {
+		if len(layer) > tallest {
+			tallest = len(layer)
+		}
+	}
+	positions := make(map[int]Point)
+	for index, layer := range layers {
+		offset := float64(tallest-len(layer)) * rowGap / 2
+		for _, state := range layer {
+			positions[state] = Point{
+				X: margin + float64(index)*layerGap,
+				Y: margin + offset + float64(order[state])*rowGap,
+			}
+		}
+	}
+	return positions
+}
+
+// assignLayers numbers the layers by breadth-first search over outgoing edges, starting from
+// the initial state and then from any state not yet reached.
+func assignLayers(d *Diagram, neighbours map[int][]int) map[int]int {
+	successors := make(map[int][]int)
+	for _, edge := range d.Edges {
+		successors[edge.Src] = append(successors[edge.Src], edge.Dst)
+	}
+
+	layerOf := make(map[int]int)
+	next := 0
+	search := func(root int) {
+		if _, seen := layerOf[root]; seen {
+			return
+		}
+		layerOf[root] = next
+		deepest := next
+		queue := []int{root}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, target := range successors[current] {
+				if _, seen := layerOf[target]; !seen {
+					layerOf[target] = layerOf[current] + 1
+					if layerOf[target] > deepest {
+						deepest = layerOf[target]
+					}
+					queue = append(queue, target)
+				}
+			}
+		}
+		next = deepest + 1
+	}
+
+	search(d.Initial)
+	for _, state := range d.States {
+		search(state.ID)
+	}
+	return layerOf
+}
+
+// orderLayers returns each state's row within its layer, sweeping left to right and back
+// and sorting every layer by the mean row of its already placed neighbours.
+func orderLayers(layers [][]int, neighbours map[int][]int) map[int]int {
+	order := make(map[int]int)
+	for _, layer := range layers {
+		for row, state := range layer {
+			order[state] = row
+		}
+	}
+
+	reorder := func(layer []int) {
+		barycenter := make(map[int]float64)
+		for _, state := range layer {
+			sum, count := 0.0, 0
+			for _, neighbour := range neighbours[state] {
+				sum += float64(order[neighbour])
+				count++
+			}
+			barycenter[state] = float64(order[state])
+			if count > 0 {
+				barycenter[state] = sum / float64(count)
+			}
+		}
+		sort.SliceStable(layer, func(i, j int) bool { return barycenter[layer[i]] < barycenter[layer[j]] })
+		for row, state := range layer {
+			order[state] = row
+		}
+	}
+
+	for sweep := 0; sweep < orderSweep; sweep++ {
+		if sweep%2 == 0 {
+			for index := 1; index < len(layers); index++ {
+				reorder(layers[index])
+			}
+		} else {
+			for index := len(layers) - 2; index >= 0; index-- {
+				reorder(layers[index])
+			}
+		}
+	}
+	return order
+}
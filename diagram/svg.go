@@ -0,0 +1,100 @@
+package diagram
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+)
+
+// Drawing sizes in canvas units.
+const (
+	radius      = 22.0
+	finalRadius = 17.0
+	startLength = 40.0
+	loopHeight  = 55.0
+	curvature   = 0.18
+)
+
+// WriteSVG lays the diagram out with Layout and writes it as a standalone SVG document.
+// Edges are quadratic curves bent to one side, so edges in opposite directions between two
+// states do not overlap.
+func WriteSVG(w io.Writer, d *Diagram) error {
+	positions := Layout(d)
+
+	width, height := 0.0, 0.0
+	for _, point := range positions {
+		width = math.Max(width, point.X+margin)
+		height = math.Max(height, point.Y+margin)
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="sans-serif" font-size="14">`+"\n", width, height, width, height)
+	fmt.Fprintln(out, `<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M0,0 L10,5 L0,10 z"/></marker></defs>`)
+	fmt.Fprintln(out, `<rect width="100%" height="100%" fill="white"/>`)
+
+	if start, ok := positions[d.Initial]; ok {
+		fmt.Fprintf(out, `<line class="start" x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="black" marker-end="url(#arrow)"/>`+"\n",
+			start.X-radius-startLength, start.Y, start.X-radius, start.Y)
+	}
+
+	for _, edge := range d.Edges {
+		src, dst := positions[edge.Src], positions[edge.Dst]
+		var path string
+		var label Point
+		if edge.Src == edge.Dst {
+			path, label = selfLoop(src)
+		} else {
+			path, label = curve(src, dst)
+		}
+		fmt.Fprintf(out, `<path class="edge" d="%s" fill="none" stroke="black" marker-end="url(#arrow)"/>`+"\n", path)
+		fmt.Fprintf(out, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`+"\n", label.X, label.Y, html.EscapeString(edge.Label))
+	}
+
+	for _, state := range d.States {
+		point := positions[state.ID]
+		fmt.Fprintf(out, `<circle class="state" cx="%.1f" cy="%.1f" r="%.0f" fill="white" stroke="black"/>`+"\n", point.X, point.Y, radius)
+		if state.Final {
+			fmt.Fprintf(out, `<circle class="final" cx="%.1f" cy="%.1f" r="%.0f" fill="none" stroke="black"/>`+"\n", point.X, point.Y, finalRadius)
+		}
+		fmt.Fprintf(out, `<text x="%.1f" y="%.1f" text-anchor="middle" dominant-baseline="central">%s</text>`+"\n", point.X, point.Y, html.EscapeString(state.Label))
+	}
+
+	fmt.Fprintln(out, "</svg>")
+	return out.Flush()
+}
+
+// curve bends the edge to the left of its direction and returns the path with a label position
+// just outside the bend.
+func curve(src, dst Point) (string, Point) {
+	dx, dy := dst.X-src.X, dst.Y-src.Y
+	length := math.Hypot(dx, dy)
+	normal := Point{X: dy / length, Y: -dx / length}
+	control := Point{X: (src.X+dst.X)/2 + normal.X*curvature*length, Y: (src.Y+dst.Y)/2 + normal.Y*curvature*length}
+
+	from := towards(src, control, radius)
+	to := towards(dst, control, radius)
+	middle := Point{X: 0.25*from.X + 0.5*control.X + 0.25*to.X, Y: 0.25*from.Y + 0.5*control.Y + 0.25*to.Y}
+	label := Point{X: middle.X + normal.X*10, Y: middle.Y + normal.Y*10 + 4}
+	return fmt.Sprintf("M%.1f,%.1f Q%.1f,%.1f %.1f,%.1f", from.X, from.Y, control.X, control.Y, to.X, to.Y), label
+}
+
+// selfLoop draws a loop above the state.
+func selfLoop(center Point) (string, Point) {
+	from := Point{X: center.X - radius*0.6, Y: center.Y - radius*0.8}
+	to := Point{X: center.X + radius*0.6, Y: center.Y - radius*0.8}
+	top := center.Y - radius - loopHeight
+	path := fmt.Sprintf("M%.1f,%.1f C%.1f,%.1f %.1f,%.1f %.1f,%.1f", from.X, from.Y, center.X-radius*1.6, top, center.X+radius*1.6, top, to.X, to.Y)
+	return path, Point{X: center.X, Y: top + 12}
+}
+
+// towards returns the point at the given distance from center in the direction of target.
+func towards(center, target Point, distance float64) Point {
+	dx, dy := target.X-center.X, target.Y-center.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return center
+	}
+	return Point{X: center.X + dx/length*distance, Y: center.Y + dy/length*distance}
+}
@@ -0,0 +1,117 @@
+package diagram
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMermaid writes the diagram as a Mermaid stateDiagram-v2. The initial state is entered
+// from [*] and final states lead to [*], which is how Mermaid marks start and end states.
+func WriteMermaid(w io.Writer, d *Diagram) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "stateDiagram-v2")
+	fmt.Fprintln(out, "\tdirection LR")
+	for _, state := range d.States {
+		fmt.Fprintf(out, "\tstate \"%s\" as %s\n", mermaidEscape(state.Label), mermaidID(state.ID))
+	}
+	fmt.Fprintf(out, "\t[*] --> %s\n", mermaidID(d.Initial))
+	for _, edge := range d.Edges {
+		fmt.Fprintf(out, "\t%s --> %s : %s\n", mermaidID(edge.Src), mermaidID(edge.Dst), mermaidEscape(edge.Label))
+	}
+	for _, state := range d.States {
+		if state.Final {
+			fmt.Fprintf(out, "\t%s --> [*]\n", mermaidID(state.ID))
+		}
+	}
+	return out.Flush()
+}
+
+func mermaidID(state int) string {
+	if state < 0 {
+		return fmt.Sprintf("n%d", -state)
+	}
+	return fmt.Sprintf("s%d", state)
+}
+
+// mermaidEscape replaces the characters Mermaid reads as syntax with its #code; entities.
+func mermaidEscape(text string) string {
+	var builder strings.Builder
+	for _, char := range text {
+		if strings.ContainsRune(`:;#"<>{}`, char) {
+			fmt.Fprintf(&builder, "#%d;", char)
+		} else {
+			builder.WriteRune(char)
+		}
+	}
+	return builder.String()
+}
+
+// Scale from Layout's canvas units to TikZ centimetres.
+const tikzScale = 60.0
+
+// WriteTikZ writes the diagram as a tikzpicture using the automata library, placed by Layout.
+// The document needs \usetikzlibrary{automata, positioning, arrows.meta}.
+func WriteTikZ(w io.Writer, d *Diagram) error {
+	positions := Layout(d)
+	reverse := make(map[[2]int]bool)
+	for _, edge := range d.Edges {
+		reverse[[2]int{edge.Src, edge.Dst}] = true
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, `\begin{tikzpicture}[shorten >=1pt, auto, >={Stealth[round]}]`)
+	for _, state := range d.States {
+		options := "state"
+		if state.ID == d.Initial {
+			options += ", initial"
+		}
+		if state.Final {
+			options += ", accepting"
+		}
+		point := positions[state.ID]
+		fmt.Fprintf(out, "\t\\node[%s] (%s) at (%.2f, %.2f) {%s};\n", options, tikzID(state.ID), point.X/tikzScale, -point.Y/tikzScale, LaTeXEscape(state.Label))
+	}
+
+	if len(d.Edges) > 0 {
+		fmt.Fprintln(out, "\t\\path[->]")
+		for i, edge := range d.Edges {
+			style := ""
+			switch {
+			case edge.Src == edge.Dst:
+				style = "[loop above]"
+			case reverse[[2]int{edge.Dst, edge.Src}]:
+				style = "[bend left]"
+			}
+			target := tikzID(edge.Dst)
+			if edge.Src == edge.Dst {
+				target = ""
+			}
+			terminator := ""
+			if i == len(d.Edges)-1 {
+				terminator = ";"
+			}
+			fmt.Fprintf(out, "\t\t(%s) edge%s node {%s} (%s)%s\n", tikzID(edge.Src), style, LaTeXEscape(edge.Label), target, terminator)
+		}
+	}
+	fmt.Fprintln(out, `\end{tikzpicture}`)
+	return out.Flush()
+}
+
+func tikzID(state int) string {
+	if state < 0 {
+		return fmt.Sprintf("n%d", -state)
+	}
+	return fmt.Sprintf("q%d", state)
+}
+
+// LaTeXEscape makes text safe in LaTeX text mode, writing ε and ∅ as math symbols.
+func LaTeXEscape(text string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\textbackslash{}`, `{`, `\{`, `}`, `\}`, `$`, `\$`, `&`, `\&`, `#`, `\#`,
+		`^`, `\^{}`, `_`, `\_`, `%`, `\%`, `~`, `\~{}`,
+		"ε", `$\varepsilon$`, "∅", `$\emptyset$`,
+	)
+	return replacer.Replace(text)
+}
@@ -1,10 +1,82 @@
 package dto
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 type RegularExpression struct {
-	RE string `json:"regular_expression"`
+	RE       string `json:"regular_expression"`
+	Dialect  string `json:"dialect,omitempty"`
+	Alphabet string `json:"alphabet,omitempty"`
+	// Construction is "thompson" (default), "glushkov", "brzozowski" or "antimirov", honoured by /convert
+	Construction string `json:"construction,omitempty"`
+	// Simplify rewrites the expression with Kleene algebra identities before /convert builds it
+	Simplify bool `json:"simplify,omitempty"`
+}
+
+// StateLimitError reports a construction abandoned because its automaton outgrew Limit states,
+// as expressions can describe automata exponentially larger than themselves. Unit names what
+// Limit counts when it is not states.
+type StateLimitError struct {
+	Construction string
+	Limit        int
+	Unit         string
+}
+
+func (e *StateLimitError) Error() string {
+	unit := e.Unit
+	if unit == "" {
+		unit = "states"
+	}
+	return fmt.Sprintf("%s exceeds %d %s", e.Construction, e.Limit, unit)
 }
 
-const Epsilon = 2
+// Symbol is an input symbol of an automaton. Every rune is a valid literal symbol, and Epsilon
+// is the empty move, which never collides with a literal.
+type Symbol string
+
+const Epsilon Symbol = ""
+
+// Literal returns the symbol that matches a single rune.
+func Literal(char rune) Symbol {
+	return Symbol(string(char))
+}
+
+// IsEpsilon reports whether the symbol is the empty move.
+func (s Symbol) IsEpsilon() bool {
+	return s == Epsilon
+}
+
+// String renders the symbol, showing Epsilon as ε.
+func (s Symbol) String() string {
+	if s.IsEpsilon() {
+		return "ε"
+	}
+	return string(s)
+}
+
+// SplitSymbols turns a string into one literal symbol per rune.
+func SplitSymbols(input string) []Symbol {
+	symbols := []Symbol{}
+	for _, char := range input {
+		symbols = append(symbols, Literal(char))
+	}
+	return symbols
+}
+
+// JoinSymbols turns a sequence of literal symbols back into a string.
+func JoinSymbols(symbols []Symbol) string {
+	var joined string
+	for _, symbol := range symbols {
+		joined += string(symbol)
+	}
+	return joined
+}
+
+// DeadState is the reserved state that rejecting runs end up in.
+const DeadState = -1
 
 type ENFAResponse struct {
 	TransitionTableSize int
@@ -12,7 +84,7 @@ type ENFAResponse struct {
 
 type TransitionKey struct {
 	SourceState int
-	InputSymbol string
+	InputSymbol Symbol
 }
 
 type StateSet map[int]bool
@@ -30,7 +102,7 @@ var (
 
 type Edge struct {
 	Src   int
-	Input int
+	Input Symbol
 	Dst   int
 }
 
@@ -49,6 +121,81 @@ type Login struct {
 	Password string `json:"password"`
 }
 
+// Table is the transition table of an automaton. Symbols names the columns, input symbols
+// ascending with ε last; rows are ordered by ascending state and Next holds the sorted
+// destinations for each column, empty when there is no transition.
+type Table struct {
+	InitialState int        `json:"initial_state"`
+	Symbols      []string   `json:"symbols"`
+	Rows         []TableRow `json:"rows"`
+}
+
+type TableRow struct {
+	State int  `json:"state"`
+	Final bool `json:"final"`
+	// NFAStates lists the NFA states a DFA state stands for
+	NFAStates []int   `json:"nfa_states,omitempty"`
+	Next      [][]int `json:"next"`
+}
+
+// Cell renders the destinations of a column as "1,3", or "NA" when there are none.
+func (r TableRow) Cell(column int) string {
+	if column >= len(r.Next) || len(r.Next[column]) == 0 {
+		return "NA"
+	}
+	destinations := make([]string, len(r.Next[column]))
+	for i, destination := range r.Next[column] {
+		destinations[i] = strconv.Itoa(destination)
+	}
+	return strings.Join(destinations, ",")
+}
+
 type TransitionTable struct {
-	TransitionTable []map[string]string `json:"transition_table"`
+	TransitionTable     Table                `json:"transition_table"`
+	Simplified          string               `json:"simplified,omitempty"`
+	SimplificationSteps []SimplificationStep `json:"simplification_steps,omitempty"`
+}
+
+type SimplificationStep struct {
+	Rule   string `json:"rule"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type MinimizedDFA struct {
+	TransitionTable    Table            `json:"transition_table"`
+	EquivalenceClasses map[string][]int `json:"equivalence_classes"`
+}
+
+type EquivalenceRequest struct {
+	First   string `json:"first"`
+	Second  string `json:"second"`
+	Dialect string `json:"dialect,omitempty"`
+}
+
+type EquivalenceResponse struct {
+	Equivalent     bool    `json:"equivalent"`
+	Counterexample *string `json:"counterexample,omitempty"`
+	AcceptedBy     string  `json:"accepted_by,omitempty"`
+}
+
+type ToRegexRequest struct {
+	TransitionTable Table  `json:"transition_table"`
+	Order           string `json:"order,omitempty"`
+	Dialect         string `json:"dialect,omitempty"`
+}
+
+type ToRegexResponse struct {
+	RE      string `json:"regular_expression"`
+	Dialect string `json:"dialect"`
+}
+
+type AutomatonValidation struct {
+	Valid         bool   `json:"valid"`
+	Error         string `json:"error,omitempty"`
+	States        int    `json:"states,omitempty"`
+	Transitions   int    `json:"transitions,omitempty"`
+	Deterministic bool   `json:"deterministic,omitempty"`
+	// TransitionTable echoes the loaded automaton so clients can check how it was read
+	TransitionTable *Table `json:"transition_table,omitempty"`
 }
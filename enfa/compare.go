@@ -0,0 +1,156 @@
+package enfa
+
+import (
+	"fmt"
+	. "github.com/jatin297/retoenfa/dto"
+	"sort"
+	"strings"
+)
+
+// maxProductStates bounds the pairs of state sets findWitness explores, since determinizing
+// both automata on the fly can take exponentially many.
+const maxProductStates = 16384
+
+// Equivalent reports whether two automata accept the same language. When they differ, witness is
+// the shortest input accepted by exactly one of them and acceptedByFirst tells which one.
+// A *StateLimitError is returned when the comparison would explore more than maxProductStates pairs.
+func Equivalent(a, b *ENFA) (equivalent bool, witness []Symbol, acceptedByFirst bool, err error) {
+	witness, found, err := findWitness(a, b, func(acceptedByA, acceptedByB bool) bool {
+		return acceptedByA != acceptedByB
+	})
+	if err != nil || !found {
+		return err == nil, nil, false, err
+	}
+	return false, witness, a.acceptsFrom(a.epsilonClosure(StateSet{a.initialState: true}), witness), nil
+}
+
+// IsSubsetOf reports whether every input accepted by a is also accepted by b. When it is not,
+// witness is the shortest input accepted by a and rejected by b.
+func IsSubsetOf(a, b *ENFA) (bool, []Symbol, error) {
+	witness, found, err := findWitness(a, b, func(acceptedByA, acceptedByB bool) bool {
+		return acceptedByA && !acceptedByB
+	})
+	return err == nil && !found, witness, err
+}
+
+// IntersectionIsEmpty reports whether no input is accepted by both a and b. When some input is,
+// witness is the shortest one.
+func IntersectionIsEmpty(a, b *ENFA) (bool, []Symbol, error) {
+	witness, found, err := findWitness(a, b, func(acceptedByA, acceptedByB bool) bool {
+		return acceptedByA && acceptedByB
+	})
+	return err == nil && !found, witness, err
+}
+
+// pairNode is a pair of reachable state sets, one per automaton, in the on-the-fly product.
+type pairNode struct {
+	first  StateSet
+	second StateSet
+	parent int
+	symbol Symbol
+}
+
+// findWitness walks both automata in lockstep, determinizing them on the fly, and returns the
+// shortest input whose acceptance by a and b satisfies the predicate. Ties are broken by the
+// sorted order of the combined alphabet.
+func findWitness(a, b *ENFA, matches func(acceptedByA, acceptedByB bool) bool) ([]Symbol, bool, error) {
+	symbolList := mergeSymbols(a.InputSymbols(), b.InputSymbols())
+
+	nodes := []pairNode{{
+		first:  a.epsilonClosure(StateSet{a.initialState: true}),
+		second: b.epsilonClosure(StateSet{b.initialState: true}),
+		parent: -1,
+	}}
+	visited := map[string]bool{pairKey(nodes[0].first, nodes[0].second): true}
+
+	for index := 0; index < len(nodes); index++ {
+		if len(nodes) > maxProductStates {
+			return nil, false, &StateLimitError{Construction: "product construction", Limit: maxProductStates}
+		}
+		current := nodes[index]
+		if matches(a.containsFinal(current.first), b.containsFinal(current.second)) {
+			return witnessFor(nodes, index), true, nil
+		}
+
+		for _, symbol := range symbolList {
+			next := pairNode{
+				first:  a.step(current.first, symbol),
+				second: b.step(current.second, symbol),
+				parent: index,
+				symbol: symbol,
+			}
+			key := pairKey(next.first, next.second)
+			if !visited[key] {
+				visited[key] = true
+				nodes = append(nodes, next)
+			}
+		}
+	}
+	return nil, false, nil
+}
+
+// step moves every state of the set on the symbol and closes the result under epsilon moves.
+func (e *ENFA) step(states StateSet, symbol Symbol) StateSet {
+	reached := make(StateSet)
+	for state := range states {
+		for dest := range e.transitions[TransitionKey{SourceState: state, InputSymbol: symbol}] {
+			reached[dest] = true
+		}
+	}
+	return e.epsilonClosure(reached)
+}
+
+func (e *ENFA) acceptsFrom(states StateSet, inputs []Symbol) bool {
+	for _, inputSymbol := range inputs {
+		states = e.step(states, inputSymbol)
+	}
+	return e.containsFinal(states)
+}
+
+func (e *ENFA) containsFinal(states StateSet) bool {
+	for state := range states {
+		if e.IsFinalState(state) {
+			return true
+		}
+	}
+	return false
+}
+
+func witnessFor(nodes []pairNode, index int) []Symbol {
+	witness := []Symbol{}
+	for ; nodes[index].parent >= 0; index = nodes[index].parent {
+		witness = append([]Symbol{nodes[index].symbol}, witness...)
+	}
+	return witness
+}
+
+func mergeSymbols(first, second []Symbol) []Symbol {
+	seen := make(map[Symbol]bool)
+	var symbolList []Symbol
+	for _, symbol := range append(append([]Symbol(nil), first...), second...) {
+		if !seen[symbol] {
+			seen[symbol] = true
+			symbolList = append(symbolList, symbol)
+		}
+	}
+	sort.Slice(symbolList, func(i, j int) bool { return symbolList[i] < symbolList[j] })
+	return symbolList
+}
+
+func pairKey(first, second StateSet) string {
+	return setKey(first) + "|" + setKey(second)
+}
+
+func setKey(states StateSet) string {
+	var stateList []int
+	for state := range states {
+		stateList = append(stateList, state)
+	}
+	sort.Ints(stateList)
+
+	parts := make([]string, len(stateList))
+	for i, state := range stateList {
+		parts[i] = fmt.Sprintf("%d", state)
+	}
+	return strings.Join(parts, ",")
+}
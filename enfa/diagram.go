@@ -0,0 +1,40 @@
+package enfa
+
+import (
+	"fmt"
+	"github.com/jatin297/retoenfa/diagram"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ExportSVG draws the automaton as an SVG state diagram laid out by the diagram package,
+// with parallel edges merged as in ExportDOT.
+func (e *ENFA) ExportSVG(w io.Writer) error {
+	return diagram.WriteSVG(w, e.Diagram())
+}
+
+// ExportMermaid writes the automaton as a Mermaid stateDiagram-v2.
+func (e *ENFA) ExportMermaid(w io.Writer) error {
+	return diagram.WriteMermaid(w, e.Diagram())
+}
+
+// ExportTikZ writes the automaton as a TikZ picture for the automata library.
+func (e *ENFA) ExportTikZ(w io.Writer) error {
+	return diagram.WriteTikZ(w, e.Diagram())
+}
+
+// Diagram describes the automaton for the diagram package, states in ascending order.
+func (e *ENFA) Diagram() *diagram.Diagram {
+	states := e.States()
+	sort.Ints(states)
+
+	d := &diagram.Diagram{Initial: e.initialState}
+	for _, state := range states {
+		d.States = append(d.States, diagram.State{ID: state, Label: fmt.Sprintf("%d", state), Final: e.IsFinalState(state)})
+	}
+	for _, edge := range e.mergedEdges() {
+		d.Edges = append(d.Edges, diagram.Edge{Src: edge.src, Dst: edge.dst, Label: strings.Join(edge.labels, ",")})
+	}
+	return d
+}
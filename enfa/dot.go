@@ -0,0 +1,74 @@
+package enfa
+
+import (
+	"bufio"
+	"fmt"
+	. "github.com/jatin297/retoenfa/dto"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ExportDOT writes the automaton as a Graphviz digraph. The initial state gets an incoming
+// arrow, final states are double circles, and parallel edges between two states are merged
+// into one edge listing every symbol, with ε for epsilon moves.
+func (e *ENFA) ExportDOT(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "digraph enfa {")
+	fmt.Fprintln(out, "\trankdir=LR;")
+	fmt.Fprintln(out, "\tnode [shape=circle];")
+	fmt.Fprintln(out, "\t__start [shape=point];")
+	fmt.Fprintf(out, "\t__start -> \"%d\";\n", e.initialState)
+
+	states := e.States()
+	sort.Ints(states)
+	for _, state := range states {
+		shape := "circle"
+		if e.IsFinalState(state) {
+			shape = "doublecircle"
+		}
+		fmt.Fprintf(out, "\t\"%d\" [shape=%s];\n", state, shape)
+	}
+
+	for _, edge := range e.mergedEdges() {
+		fmt.Fprintf(out, "\t\"%d\" -> \"%d\" [label=%s];\n", edge.src, edge.dst, quoteDOT(strings.Join(edge.labels, ",")))
+	}
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
+
+// mergedEdge is every transition between one pair of states, labels sorted with ε last.
+type mergedEdge struct {
+	src    int
+	dst    int
+	labels []string
+}
+
+func (e *ENFA) mergedEdges() []mergedEdge {
+	symbols := append(e.InputSymbols(), Epsilon)
+	var edges []mergedEdge
+	for _, src := range e.States() {
+		index := make(map[int]int)
+		for _, symbol := range symbols {
+			for _, dst := range e.NextStates(src, symbol) {
+				if _, ok := index[dst]; !ok {
+					index[dst] = len(edges)
+					edges = append(edges, mergedEdge{src: src, dst: dst})
+				}
+				edges[index[dst]].labels = append(edges[index[dst]].labels, symbol.String())
+			}
+		}
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].src != edges[j].src {
+			return edges[i].src < edges[j].src
+		}
+		return edges[i].dst < edges[j].dst
+	})
+	return edges
+}
+
+// quoteDOT renders a string as a DOT quoted identifier.
+func quoteDOT(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
@@ -3,26 +3,24 @@ package enfa
 import (
 	"fmt"
 	. "github.com/jatin297/retoenfa/dto"
-	"strings"
+	"sort"
 )
 
 // CreateENFA initializes a new ENFA with the given initial state and final state designation.
 func CreateENFA(initialState int, isFinal bool) *ENFA {
 	newENFA := &ENFA{
 		initialState: initialState,
-		activeStates: make(StateSet),
 		states:       []int{},
 		transitions:  make(map[TransitionKey]StateSet),
-		inputSymbols: make(map[string]bool),
+		inputSymbols: make(map[Symbol]bool),
 	}
-	newENFA.activeStates[initialState] = true
 	newENFA.InsertState(initialState, isFinal)
 	return newENFA
 }
 
 // InsertState adds a new state to the ENFA.
 func (e *ENFA) InsertState(state int, isFinal bool) {
-	if state == -1 {
+	if state == DeadState {
 		fmt.Println("State -1 is reserved for the dead state and cannot be added.")
 		return
 	}
@@ -32,8 +30,9 @@ func (e *ENFA) InsertState(state int, isFinal bool) {
 	}
 }
 
-// DefineTransition sets up a transition between states based on an input symbol.
-func (e *ENFA) DefineTransition(startState int, symbol string, endStates ...int) {
+// DefineTransition sets up a transition between states based on an input symbol. Destinations
+// are added to the ones already defined for the same state and symbol.
+func (e *ENFA) DefineTransition(startState int, symbol Symbol, endStates ...int) {
 	if _, found := e.inputSymbols[symbol]; !found {
 		e.inputSymbols[symbol] = true
 	}
@@ -51,49 +50,46 @@ func (e *ENFA) DefineTransition(startState int, symbol string, endStates ...int)
 		return
 	}
 
-	destinationStates := make(StateSet)
+	key := TransitionKey{SourceState: startState, InputSymbol: symbol}
+	destinationStates, exists := e.transitions[key]
+	if !exists {
+		destinationStates = make(StateSet)
+		e.transitions[key] = destinationStates
+	}
 	for _, destination := range endStates {
 		destinationStates[destination] = true
 	}
+}
 
-	e.transitions[TransitionKey{startState, symbol}] = destinationStates
+// AddInputSymbol declares a symbol as part of the input alphabet, even if no transition uses it.
+func (e *ENFA) AddInputSymbol(symbol Symbol) {
+	if !symbol.IsEpsilon() {
+		e.inputSymbols[symbol] = true
+	}
 }
 
 // IsPathExists checks if a transition exists between two states for the given input symbol.
-func (e *ENFA) IsPathExists(source int, input string, destination int) bool {
-	if destSet, exists := e.transitions[TransitionKey{source, input}]; exists {
+func (e *ENFA) IsPathExists(source int, input Symbol, destination int) bool {
+	if destSet, exists := e.transitions[TransitionKey{SourceState: source, InputSymbol: input}]; exists {
 		_, found := destSet[destination]
 		return found
 	}
 	return false
 }
 
-// DisplayTransitions outputs the ENFA's transition table.
+// DisplayTransitions outputs the ENFA's transition table in the order of GenerateFormattedTransitionTable.
 func (e *ENFA) DisplayTransitions() {
+	table := e.GenerateFormattedTransitionTable()
 	fmt.Println("===========================================")
-	var symbolList []string
-	for symbol := range e.inputSymbols {
-		if symbol == "" {
-			fmt.Printf("\tε|")
-		} else {
-			fmt.Printf("\t%s|", symbol)
-		}
-		symbolList = append(symbolList, symbol)
+	for _, symbol := range table.Symbols {
+		fmt.Printf("\t%s|", symbol)
 	}
 	fmt.Println("\n-------------------------------------------")
 
-	for _, state := range e.states {
-		fmt.Printf("%d |", state)
-		for _, symbol := range symbolList {
-			if destSet, exists := e.transitions[TransitionKey{state, symbol}]; exists {
-				fmt.Printf("\t")
-				for dest := range destSet {
-					fmt.Printf("%d,", dest)
-				}
-				fmt.Print("|")
-			} else {
-				fmt.Print("\tNA|")
-			}
+	for _, row := range table.Rows {
+		fmt.Printf("%d |", row.State)
+		for column := range table.Symbols {
+			fmt.Printf("\t%s|", row.Cell(column))
 		}
 		fmt.Println()
 	}
@@ -101,89 +97,239 @@ func (e *ENFA) DisplayTransitions() {
 	fmt.Println("===========================================")
 }
 
-// ProcessInput processes a single input symbol and updates the active states of the ENFA.
-func (e *ENFA) ProcessInput(input string) []int {
-	newActiveStates := make(StateSet)
-	for active := range e.activeStates {
-		if nextStates, exists := e.transitions[TransitionKey{active, input}]; exists {
-			for dest := range nextStates {
-				newActiveStates[dest] = true
-				// Handle epsilon transitions
-				if epsilonStates, hasEpsilon := e.transitions[TransitionKey{dest, ""}]; hasEpsilon {
-					for epsilonDest := range epsilonStates {
-						newActiveStates[epsilonDest] = true
-					}
-				}
-			}
-		}
-	}
-	e.activeStates = newActiveStates
-	var resultStates []int
-	for state := range newActiveStates {
-		resultStates = append(resultStates, state)
+// SetInitialState makes the given state the start state and resets the deprecated run state.
+func (e *ENFA) SetInitialState(state int) {
+	e.initialState = state
+	e.run = nil
+}
+
+// MarkFinalState designates an existing state as final.
+func (e *ENFA) MarkFinalState(state int) {
+	if !e.IsFinalState(state) {
+		e.finalStates = append(e.finalStates, state)
 	}
-	return resultStates
 }
 
 type ENFA struct {
 	initialState int
-	activeStates StateSet
 	states       []int
 	finalStates  []int
 	transitions  map[TransitionKey]StateSet
-	inputSymbols map[string]bool
+	inputSymbols map[Symbol]bool
+	// run backs the deprecated ProcessInput, CheckIfFinalState and ReinitializeActiveStates
+	run *Runner
+}
+
+// ProcessInput processes a single input symbol and returns the sorted active states. The run
+// steps through a snapshot compiled on the first call, so transitions defined afterwards are
+// ignored until ReinitializeActiveStates.
+//
+// Deprecated: the run state is shared by every caller of the ENFA. Use Compile and a Runner.
+func (e *ENFA) ProcessInput(input Symbol) []int {
+	return e.runner().Step(input)
 }
 
 // CheckIfFinalState verifies if any of the active states is a final state.
+//
+// Deprecated: use Compile and Runner.Accepting.
 func (e *ENFA) CheckIfFinalState() bool {
+	return e.runner().Accepting()
+}
+
+// ReinitializeActiveStates sets the active states back to the initial state. Changes made to
+// the ENFA since the run started take effect from here.
+//
+// Deprecated: use Compile and Runner.Reset.
+func (e *ENFA) ReinitializeActiveStates() {
+	e.run = nil
+}
+
+// runner returns the run of the deprecated methods, starting one on a fresh compilation.
+func (e *ENFA) runner() *Runner {
+	if e.run == nil {
+		e.run = e.Compile().NewRunner()
+	}
+	return e.run
+}
+
+// ValidateInputSequence determines whether the ENFA accepts a given sequence of input symbols.
+// Every call starts from the initial state and leaves the ENFA untouched; use Compile and a
+// Runner to follow a run symbol by symbol.
+func (e *ENFA) ValidateInputSequence(inputs []Symbol) bool {
+	return e.acceptsFrom(e.epsilonClosure(StateSet{e.initialState: true}), inputs)
+}
+
+// GenerateFormattedTransitionTable creates a structured view of the transition table with states
+// ascending, input symbols ascending, ε last and sorted destinations, so equal automata give
+// equal tables.
+func (e *ENFA) GenerateFormattedTransitionTable() Table {
+	symbols := e.InputSymbols()
+	if e.inputSymbols[Epsilon] {
+		symbols = append(symbols, Epsilon)
+	}
+
+	table := Table{InitialState: e.initialState, Symbols: make([]string, len(symbols)), Rows: []TableRow{}}
+	for i, symbol := range symbols {
+		table.Symbols[i] = symbol.String()
+	}
+
+	states := e.States()
+	sort.Ints(states)
+	for _, state := range states {
+		row := TableRow{State: state, Final: e.IsFinalState(state), Next: make([][]int, len(symbols))}
+		for i, symbol := range symbols {
+			row.Next[i] = append([]int{}, e.NextStates(state, symbol)...)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
+
+// InitialState returns the state the ENFA starts from.
+func (e *ENFA) InitialState() int {
+	return e.initialState
+}
+
+// States returns the states of the ENFA in insertion order.
+func (e *ENFA) States() []int {
+	return append([]int(nil), e.states...)
+}
+
+// IsFinalState reports whether the given state is a final state.
+func (e *ENFA) IsFinalState(state int) bool {
 	for _, finalState := range e.finalStates {
-		if e.activeStates[finalState] {
+		if finalState == state {
 			return true
 		}
 	}
 	return false
 }
 
-// ReinitializeActiveStates sets the active states back to the initial state.
-func (e *ENFA) ReinitializeActiveStates() {
-	e.activeStates = StateSet{e.initialState: true}
+// InputSymbols returns the sorted input alphabet of the ENFA, excluding epsilon.
+func (e *ENFA) InputSymbols() []Symbol {
+	var symbolList []Symbol
+	for symbol := range e.inputSymbols {
+		if !symbol.IsEpsilon() {
+			symbolList = append(symbolList, symbol)
+		}
+	}
+	sort.Slice(symbolList, func(i, j int) bool { return symbolList[i] < symbolList[j] })
+	return symbolList
 }
 
-// ValidateInputSequence determines whether the ENFA accepts a given sequence of input symbols.
-func (e *ENFA) ValidateInputSequence(inputs []string) bool {
-	for _, inputSymbol := range inputs {
-		e.ProcessInput(inputSymbol)
+// NextStates returns the sorted destinations of a transition, or nil if none is defined.
+func (e *ENFA) NextStates(state int, symbol Symbol) []int {
+	var destList []int
+	for dest := range e.transitions[TransitionKey{SourceState: state, InputSymbol: symbol}] {
+		destList = append(destList, dest)
 	}
-	return e.CheckIfFinalState()
+	sort.Ints(destList)
+	return destList
 }
 
-// GenerateFormattedTransitionTable creates a structured view of the transition table.
-func (e *ENFA) GenerateFormattedTransitionTable() []map[string]string {
-	var symbolList []string
-	for symbol := range e.inputSymbols {
-		symbolList = append(symbolList, symbol)
+// maxEpsilonFreeTransitions bounds the transitions RemoveEpsilons writes out, since every state
+// takes over the moves of its whole epsilon closure.
+const maxEpsilonFreeTransitions = 1 << 16
+
+// RemoveEpsilons returns an equivalent automaton without epsilon transitions. Only the initial
+// state and the states entered on a symbol are kept, under their own numbers; each moves on a
+// symbol from its epsilon closure and becomes final when its closure contains a final state.
+// A *StateLimitError is returned when the result would exceed maxEpsilonFreeTransitions.
+func (e *ENFA) RemoveEpsilons() (*ENFA, error) {
+	closures := make(map[int]StateSet)
+	closureOf := func(state int) StateSet {
+		closure, found := closures[state]
+		if !found {
+			closure = e.epsilonClosure(StateSet{state: true})
+			closures[state] = closure
+		}
+		return closure
 	}
 
-	var table []map[string]string
+	kept := []int{e.initialState}
+	isKept := StateSet{e.initialState: true}
 	for _, state := range e.states {
-		row := make(map[string]string)
-		row["state"] = fmt.Sprintf("%d", state)
-		for _, symbol := range symbolList {
-			destSet, exists := e.transitions[TransitionKey{state, symbol}]
-			if len(symbol) == 0 {
-				symbol = "ε"
+		for symbol := range e.inputSymbols {
+			if symbol.IsEpsilon() {
+				continue
 			}
-			if exists {
-				var destList []string
-				for dest := range destSet {
-					destList = append(destList, fmt.Sprintf("%d", dest))
+			for dest := range e.transitions[TransitionKey{SourceState: state, InputSymbol: symbol}] {
+				if !isKept[dest] {
+					isKept[dest] = true
+					kept = append(kept, dest)
 				}
-				row[symbol] = strings.Join(destList, ",")
-			} else {
-				row[symbol] = "NA"
 			}
 		}
-		table = append(table, row)
 	}
-	return table
+	sort.Ints(kept[1:])
+
+	result := CreateENFA(e.initialState, e.containsFinal(closureOf(e.initialState)))
+	for _, state := range kept[1:] {
+		result.InsertState(state, e.containsFinal(closureOf(state)))
+	}
+
+	symbolList := e.InputSymbols()
+	transitions := 0
+	for _, state := range kept {
+		closure := closureOf(state)
+		for _, symbol := range symbolList {
+			reached := make(StateSet)
+			for source := range closure {
+				for dest := range e.transitions[TransitionKey{SourceState: source, InputSymbol: symbol}] {
+					reached[dest] = true
+				}
+			}
+			if len(reached) == 0 {
+				continue
+			}
+			if transitions += len(reached); transitions > maxEpsilonFreeTransitions {
+				return nil, &StateLimitError{Construction: "epsilon removal", Limit: maxEpsilonFreeTransitions, Unit: "transitions"}
+			}
+
+			destList := make([]int, 0, len(reached))
+			for dest := range reached {
+				destList = append(destList, dest)
+			}
+			sort.Ints(destList)
+			result.DefineTransition(state, symbol, destList...)
+		}
+	}
+	return result, nil
+}
+
+// EpsilonClosure returns the sorted set of states reachable from the given states through
+// epsilon transitions only, the given states included.
+func (e *ENFA) EpsilonClosure(states []int) []int {
+	initial := make(StateSet)
+	for _, state := range states {
+		initial[state] = true
+	}
+
+	var closure []int
+	for state := range e.epsilonClosure(initial) {
+		closure = append(closure, state)
+	}
+	sort.Ints(closure)
+	return closure
+}
+
+// epsilonClosure returns the states reachable from the given states through epsilon transitions only.
+func (e *ENFA) epsilonClosure(states StateSet) StateSet {
+	closure := make(StateSet)
+	var stack []int
+	for state := range states {
+		stack = append(stack, state)
+	}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if closure[current] {
+			continue
+		}
+		closure[current] = true
+		for dest := range e.transitions[TransitionKey{SourceState: current, InputSymbol: Epsilon}] {
+			stack = append(stack, dest)
+		}
+	}
+	return closure
 }
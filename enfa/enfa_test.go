@@ -1,7 +1,12 @@
 package enfa
 
 import (
+	"encoding/json"
+	"errors"
+	. "github.com/jatin297/retoenfa/dto"
 	"github.com/stretchr/testify/suite"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -53,7 +58,7 @@ func (suite *ENFATestSuite) TestValidateInputSequence() {
 	nfa.DefineTransition(0, "a", 1)
 	nfa.DefineTransition(1, "b", 2)
 
-	var inputs []string
+	var inputs []Symbol
 	inputs = append(inputs, "a")
 	inputs = append(inputs, "b")
 	if !nfa.ValidateInputSequence(inputs) {
@@ -79,7 +84,7 @@ func (suite *ENFATestSuite) TestAdvanceENFA() {
 	nfa.DefineTransition(2, "1", 2)
 
 	nfa.GenerateFormattedTransitionTable()
-	inputs := []string{"0", "0", "1", "0", "1"}
+	inputs := []Symbol{"0", "0", "1", "0", "1"}
 
 	if !nfa.ValidateInputSequence(inputs) {
 		t.Errorf("Verify inputs is failed")
@@ -90,7 +95,7 @@ func (suite *ENFATestSuite) TestAdvanceENFA() {
 
 	//Test go to dead state 2
 
-	inputs2 := []string{"1", "1", "0", "0", "0"}
+	inputs2 := []Symbol{"1", "1", "0", "0", "0"}
 
 	if nfa.ValidateInputSequence(inputs2) {
 		t.Errorf("Verify inputs is failed")
@@ -112,20 +117,20 @@ func (suite *ENFATestSuite) TestNFA() {
 	nfa.DefineTransition(2, "0", 2)
 	nfa.DefineTransition(2, "1", 2, 0)
 	nfa.GenerateFormattedTransitionTable()
-	inputs := []string{"0", "0", "1", "0", "1"}
+	inputs := []Symbol{"0", "0", "1", "0", "1"}
 
 	if !nfa.ValidateInputSequence(inputs) {
 		t.Errorf("Verify inputs is failed")
 	}
 
-	inputs2 := []string{"0", "0", "0", "0", "1"}
+	inputs2 := []Symbol{"0", "0", "0", "0", "1"}
 
 	if !nfa.ValidateInputSequence(inputs2) {
 		t.Errorf("Verify inputs2 is failed")
 
 	}
 
-	inputs3 := []string{"0", "1", "2"}
+	inputs3 := []Symbol{"0", "1", "2"}
 
 	if nfa.ValidateInputSequence(inputs3) {
 		t.Errorf("Verify inputs3 is failed")
@@ -155,24 +160,506 @@ func (suite *ENFATestSuite) TestEpsilonNFA() {
 
 	nfa.GenerateFormattedTransitionTable()
 
-	if !nfa.ValidateInputSequence([]string{"1"}) {
+	if !nfa.ValidateInputSequence([]Symbol{"1"}) {
 		t.Errorf("Verify inputs is failed")
 	}
 
 	nfa.ReinitializeActiveStates()
 
-	if !nfa.ValidateInputSequence([]string{"1", "1", "1"}) {
+	if !nfa.ValidateInputSequence([]Symbol{"1", "1", "1"}) {
 		t.Errorf("Verify inputs is failed")
 	}
 
 	nfa.ReinitializeActiveStates()
 
-	if !nfa.ValidateInputSequence([]string{"0", "1"}) {
+	if !nfa.ValidateInputSequence([]Symbol{"0", "1"}) {
 		t.Errorf("Verify inputs is failed")
 	}
 
 	nfa.ReinitializeActiveStates()
-	if !nfa.ValidateInputSequence([]string{"0", "0", "0"}) {
+	if !nfa.ValidateInputSequence([]Symbol{"0", "0", "0"}) {
 		t.Errorf("Verify inputs is failed")
 	}
 }
+
+func (suite *ENFATestSuite) TestRemoveEpsilons() {
+	suite.SetupTest()
+	t := suite.T()
+
+	nfa := suite.enfa
+	nfa.InsertState(1, false)
+	nfa.InsertState(2, false)
+	nfa.InsertState(3, true)
+	nfa.InsertState(4, false)
+	nfa.InsertState(5, false)
+	nfa.InsertState(6, false)
+
+	nfa.DefineTransition(0, "1", 1)
+	nfa.DefineTransition(0, "0", 4)
+	nfa.DefineTransition(1, "1", 2)
+	nfa.DefineTransition(1, "", 3)
+	nfa.DefineTransition(2, "1", 3)
+	nfa.DefineTransition(4, "0", 5)
+	nfa.DefineTransition(4, "", 1, 2)
+	nfa.DefineTransition(5, "0", 3)
+	nfa.DefineTransition(5, "", 6)
+	nfa.DefineTransition(6, "1", 3)
+
+	withoutEpsilon, err := nfa.RemoveEpsilons()
+	if err != nil {
+		t.Fatalf("Expect no error, but get %v", err)
+	}
+
+	if states := withoutEpsilon.States(); len(states) != 6 || states[5] != 5 {
+		t.Errorf("Expect the epsilon-only state 6 to be dropped, but get %v", states)
+	}
+
+	for _, state := range withoutEpsilon.States() {
+		if dest := withoutEpsilon.NextStates(state, ""); len(dest) != 0 {
+			t.Errorf("Expect no epsilon transitions from %d, but get %v", state, dest)
+		}
+	}
+
+	for state, isFinal := range map[int]bool{0: false, 1: true, 2: false, 3: true, 4: true, 5: false} {
+		if withoutEpsilon.IsFinalState(state) != isFinal {
+			t.Errorf("Expect final=%t for state %d", isFinal, state)
+		}
+	}
+
+	if dest := withoutEpsilon.NextStates(4, "1"); len(dest) != 2 || dest[0] != 2 || dest[1] != 3 {
+		t.Errorf("Expect 4 -1-> {2,3}, but get %v", dest)
+	}
+
+	if symbols := withoutEpsilon.GenerateFormattedTransitionTable().Symbols; symbols[len(symbols)-1] == "ε" {
+		t.Errorf("Expect no epsilon column in the transition table")
+	}
+
+	if !withoutEpsilon.ValidateInputSequence([]Symbol{"0", "1"}) {
+		t.Errorf("Verify inputs is failed")
+	}
+
+	withoutEpsilon.ReinitializeActiveStates()
+	if !withoutEpsilon.ValidateInputSequence([]Symbol{"0", "0", "0"}) {
+		t.Errorf("Verify inputs is failed")
+	}
+
+	withoutEpsilon.ReinitializeActiveStates()
+	if !withoutEpsilon.ValidateInputSequence([]Symbol{"0", "0", "1"}) {
+		t.Errorf("Verify inputs is failed")
+	}
+
+	withoutEpsilon.ReinitializeActiveStates()
+	if withoutEpsilon.ValidateInputSequence([]Symbol{"0", "0"}) {
+		t.Errorf("Verify inputs is failed")
+	}
+}
+
+func (suite *ENFATestSuite) TestRemoveEpsilonsLimit() {
+	suite.SetupTest()
+	t := suite.T()
+
+	// every state of an epsilon chain takes over the moves of all the states after it
+	nfa := suite.enfa
+	for state := 1; state < 400; state++ {
+		nfa.InsertState(state, false)
+		nfa.DefineTransition(state-1, "", state)
+	}
+	for state := 0; state < 400; state++ {
+		nfa.DefineTransition(state, "a", state)
+	}
+
+	var limitErr *StateLimitError
+	if _, err := nfa.RemoveEpsilons(); !errors.As(err, &limitErr) || limitErr.Limit != maxEpsilonFreeTransitions {
+		t.Errorf("Expect a StateLimitError, but get %v", err)
+	}
+}
+
+func (suite *ENFATestSuite) TestEquivalent() {
+	suite.SetupTest()
+	t := suite.T()
+
+	// (ab)* written directly
+	first := CreateENFA(0, true)
+	first.InsertState(1, false)
+	first.DefineTransition(0, "a", 1)
+	first.DefineTransition(1, "b", 0)
+
+	// (ab)* behind an epsilon move
+	second := CreateENFA(0, false)
+	second.InsertState(1, true)
+	second.InsertState(2, false)
+	second.DefineTransition(0, "", 1)
+	second.DefineTransition(1, "a", 2)
+	second.DefineTransition(2, "b", 1)
+
+	if equivalent, witness, _, _ := Equivalent(first, second); !equivalent {
+		t.Errorf("Expect automata to be equivalent, but get witness %v", witness)
+	}
+
+	// (ab)*(e+a)
+	third := CreateENFA(0, true)
+	third.InsertState(1, true)
+	third.DefineTransition(0, "a", 1)
+	third.DefineTransition(1, "b", 0)
+
+	equivalent, witness, acceptedByFirst, _ := Equivalent(first, third)
+	if equivalent {
+		t.Fatalf("Expect automata to differ")
+	}
+	if JoinSymbols(witness) != "a" || acceptedByFirst {
+		t.Errorf("Expect shortest witness a accepted by the second, but get %v (first=%t)", witness, acceptedByFirst)
+	}
+
+	// The empty string is the shortest possible witness
+	equivalent, witness, acceptedByFirst, _ = Equivalent(second, suite.enfa)
+	if equivalent || len(witness) != 0 || !acceptedByFirst {
+		t.Errorf("Expect empty witness accepted by the first, but get %v (first=%t)", witness, acceptedByFirst)
+	}
+}
+
+func (suite *ENFATestSuite) TestInclusionAndIntersection() {
+	suite.SetupTest()
+	t := suite.T()
+
+	// a.b*
+	narrow := CreateENFA(0, false)
+	narrow.InsertState(1, true)
+	narrow.DefineTransition(0, "a", 1)
+	narrow.DefineTransition(1, "b", 1)
+
+	// (a+b)*
+	wide := CreateENFA(0, true)
+	wide.DefineTransition(0, "a", 0)
+	wide.DefineTransition(0, "b", 0)
+
+	if subset, witness, _ := IsSubsetOf(narrow, wide); !subset {
+		t.Errorf("Expect a.b* to be included in (a+b)*, but get witness %v", witness)
+	}
+
+	subset, witness, _ := IsSubsetOf(wide, narrow)
+	if subset || witness == nil || len(witness) != 0 {
+		t.Errorf("Expect empty string as witness, but get %v", witness)
+	}
+
+	// b.b*
+	onlyB := CreateENFA(0, false)
+	onlyB.InsertState(1, true)
+	onlyB.DefineTransition(0, "b", 1)
+	onlyB.DefineTransition(1, "b", 1)
+
+	if empty, witness, _ := IntersectionIsEmpty(narrow, onlyB); !empty {
+		t.Errorf("Expect disjoint languages, but get witness %v", witness)
+	}
+
+	empty, witness, _ := IntersectionIsEmpty(wide, onlyB)
+	if empty || JoinSymbols(witness) != "b" {
+		t.Errorf("Expect shared witness b, but get %v", witness)
+	}
+}
+
+func (suite *ENFATestSuite) TestTransitiveEpsilonClosure() {
+	suite.SetupTest()
+	t := suite.T()
+
+	nfa := suite.enfa
+	nfa.InsertState(1, false)
+	nfa.InsertState(2, false)
+	nfa.InsertState(3, false)
+	nfa.InsertState(4, true)
+
+	nfa.DefineTransition(0, "", 1)
+	nfa.DefineTransition(1, "", 2)
+	nfa.DefineTransition(2, "a", 3)
+	nfa.DefineTransition(3, "", 1)
+	nfa.DefineTransition(1, "", 4)
+
+	if closure := nfa.EpsilonClosure([]int{0}); len(closure) != 4 || closure[0] != 0 || closure[3] != 4 {
+		t.Errorf("Expect closure {0,1,2,4}, but get %v", closure)
+	}
+
+	if !nfa.ValidateInputSequence(nil) {
+		t.Errorf("Expect the empty input to be accepted through the initial closure")
+	}
+
+	nfa.ReinitializeActiveStates()
+	if !nfa.ValidateInputSequence([]Symbol{"a", "a"}) {
+		t.Errorf("Expect a.a to be accepted through two epsilon hops")
+	}
+
+	nfa.ReinitializeActiveStates()
+	if nfa.ValidateInputSequence([]Symbol{"b"}) {
+		t.Errorf("Expect b to be rejected")
+	}
+}
+
+func (suite *ENFATestSuite) TestFromTransitionTable() {
+	suite.SetupTest()
+	t := suite.T()
+
+	nfa := suite.enfa
+	nfa.InsertState(1, false)
+	nfa.InsertState(2, true)
+	nfa.DefineTransition(0, "", 1)
+	nfa.DefineTransition(1, "a", 1, 2)
+	nfa.DefineTransition(2, "b", 0)
+	nfa.AddInputSymbol("c")
+
+	table := nfa.GenerateFormattedTransitionTable()
+	suite.Equal([]string{"a", "b", "c", "ε"}, table.Symbols)
+	suite.Equal([]int{1, 2}, table.Rows[1].Next[0])
+	suite.Equal("1,2", table.Rows[1].Cell(0))
+	suite.Equal("NA", table.Rows[1].Cell(1))
+
+	rebuilt, err := FromTransitionTable(table)
+	if err != nil {
+		t.Fatalf("Expect the table to load, but get %v", err)
+	}
+	if equivalent, witness, _, _ := Equivalent(nfa, rebuilt); !equivalent {
+		t.Errorf("Expect the rebuilt automaton to be equivalent, but get witness %v", witness)
+	}
+	if symbols := rebuilt.InputSymbols(); len(symbols) != 3 {
+		t.Errorf("Expect the unused symbol c to be kept, but get %v", symbols)
+	}
+
+	invalid := []Table{
+		{InitialState: 0, Symbols: []string{"a"}, Rows: []TableRow{{State: 0, Next: [][]int{{}}}, {State: 0, Next: [][]int{{}}}}},
+		{InitialState: 0, Symbols: []string{"a"}, Rows: []TableRow{{State: 0, Next: [][]int{{5}}}}},
+		{InitialState: 0, Symbols: []string{"ab"}, Rows: []TableRow{{State: 0, Next: [][]int{{0}}}}},
+		{InitialState: 0, Symbols: []string{"a"}, Rows: []TableRow{{State: 0, Next: [][]int{}}}},
+		{InitialState: 1, Symbols: []string{"a"}, Rows: []TableRow{{State: 0, Next: [][]int{{0}}}}},
+	}
+	for _, table := range invalid {
+		if _, err := FromTransitionTable(table); err == nil {
+			t.Errorf("Expect %v to be rejected", table)
+		}
+	}
+}
+
+func (suite *ENFATestSuite) TestExportDOT() {
+	suite.SetupTest()
+	t := suite.T()
+
+	nfa := suite.enfa
+	nfa.InsertState(1, false)
+	nfa.InsertState(2, true)
+	nfa.DefineTransition(0, "", 1)
+	nfa.DefineTransition(0, "b", 1)
+	nfa.DefineTransition(0, "a", 1)
+	nfa.DefineTransition(1, `"`, 2)
+
+	var out strings.Builder
+	if err := nfa.ExportDOT(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected := `digraph enfa {
+	rankdir=LR;
+	node [shape=circle];
+	__start [shape=point];
+	__start -> "0";
+	"0" [shape=circle];
+	"1" [shape=circle];
+	"2" [shape=doublecircle];
+	"0" -> "1" [label="a,b,ε"];
+	"1" -> "2" [label="\""];
+}
+`
+	suite.Equal(expected, out.String())
+}
+
+func (suite *ENFATestSuite) TestJSONRoundTrip() {
+	suite.SetupTest()
+	t := suite.T()
+
+	nfa := suite.enfa
+	nfa.InsertState(2, true)
+	nfa.InsertState(1, false)
+	nfa.DefineTransition(0, "", 1)
+	nfa.DefineTransition(1, "a", 2, 1)
+	nfa.DefineTransition(2, "b", 0)
+	nfa.AddInputSymbol("c")
+
+	data, err := json.Marshal(nfa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	suite.JSONEq(`{
+		"states": [0, 2, 1],
+		"initial_state": 0,
+		"final_states": [2],
+		"alphabet": ["a", "b", "c"],
+		"transitions": [
+			{"from": 0, "symbol": "", "to": [1]},
+			{"from": 1, "symbol": "a", "to": [1, 2]},
+			{"from": 2, "symbol": "b", "to": [0]}
+		]
+	}`, string(data))
+
+	var decoded ENFA
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	suite.Equal(nfa.States(), decoded.States())
+	suite.Equal(nfa.GenerateFormattedTransitionTable(), decoded.GenerateFormattedTransitionTable())
+	suite.False(decoded.IsDeterministic())
+	suite.Equal(4, decoded.TransitionCount())
+	if !decoded.ValidateInputSequence([]Symbol{"a", "b", "a"}) {
+		t.Errorf("Expect the decoded automaton to run")
+	}
+
+	invalid := []string{
+		`{"states": [0, 0], "initial_state": 0}`,
+		`{"states": [-1], "initial_state": -1}`,
+		`{"states": [0], "initial_state": 1}`,
+		`{"states": [0], "initial_state": 0, "final_states": [3]}`,
+		`{"states": [0], "initial_state": 0, "alphabet": ["ab"]}`,
+		`{"states": [0], "initial_state": 0, "transitions": [{"from": 0, "symbol": "a", "to": [0]}]}`,
+		`{"states": [0], "initial_state": 0, "alphabet": ["a"], "transitions": [{"from": 0, "symbol": "a", "to": [7]}]}`,
+	}
+	for _, data := range invalid {
+		var rejected ENFA
+		if err := json.Unmarshal([]byte(data), &rejected); err == nil {
+			t.Errorf("Expect %s to be rejected", data)
+		}
+	}
+}
+
+func (suite *ENFATestSuite) TestJFLAP() {
+	suite.SetupTest()
+	t := suite.T()
+
+	nfa := suite.enfa
+	nfa.InsertState(1, false)
+	nfa.InsertState(2, true)
+	nfa.DefineTransition(0, "", 1)
+	nfa.DefineTransition(1, "a", 1, 2)
+	nfa.DefineTransition(2, "<", 0)
+
+	var out strings.Builder
+	if err := nfa.ExportJFLAP(&out); err != nil {
+		t.Fatal(err)
+	}
+	suite.Contains(out.String(), "<type>fa</type>")
+	suite.Contains(out.String(), "<initial></initial>")
+	suite.Contains(out.String(), "<read>&lt;</read>")
+
+	imported, err := ImportJFLAP(strings.NewReader(out.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	suite.Equal(nfa.GenerateFormattedTransitionTable(), imported.GenerateFormattedTransitionTable())
+
+	// Files from JFLAP 6 have no automaton element and write λ moves as an empty read
+	legacy := `<?xml version="1.0" encoding="UTF-8" standalone="no"?><structure>
+		<type>fa</type>
+		<state id="3" name="q3"><x>50.0</x><y>80.0</y><initial/></state>
+		<state id="7" name="q7"><x>150.0</x><y>80.0</y><final/></state>
+		<transition><from>3</from><to>7</to><read/></transition>
+		<transition><from>7</from><to>7</to><read>b</read></transition>
+	</structure>`
+	imported, err = ImportJFLAP(strings.NewReader(legacy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	suite.Equal(3, imported.InitialState())
+	suite.Equal([]int{7}, imported.NextStates(3, Epsilon))
+	if !imported.ValidateInputSequence([]Symbol{"b", "b"}) {
+		t.Errorf("Expect the legacy automaton to accept b.b")
+	}
+
+	invalid := []string{
+		`<structure><type>turing</type></structure>`,
+		`<structure><type>fa</type><state id="0"/></structure>`,
+		`<structure><type>fa</type><state id="0"><initial/></state><state id="0"/></structure>`,
+		`<structure><type>fa</type><state id="0"><initial/></state><transition><from>0</from><to>1</to><read>a</read></transition></structure>`,
+		`<structure><type>fa</type><state id="0"><initial/></state><transition><from>0</from><to>0</to><read>ab</read></transition></structure>`,
+		`not xml`,
+	}
+	for _, document := range invalid {
+		if _, err := ImportJFLAP(strings.NewReader(document)); err == nil {
+			t.Errorf("Expect %s to be rejected", document)
+		}
+	}
+}
+
+func (suite *ENFATestSuite) TestCompiledRunners() {
+	suite.SetupTest()
+	t := suite.T()
+
+	// (a+b)*.a.b with an epsilon hop into the loop
+	nfa := suite.enfa
+	nfa.InsertState(1, false)
+	nfa.InsertState(2, false)
+	nfa.InsertState(3, true)
+	nfa.DefineTransition(0, "", 1)
+	nfa.DefineTransition(1, "a", 1, 2)
+	nfa.DefineTransition(1, "b", 1)
+	nfa.DefineTransition(2, "b", 3)
+
+	compiled := nfa.Compile()
+	nfa.MarkFinalState(0)
+	if compiled.Match(nil) {
+		t.Errorf("Expect the compiled automaton to ignore later changes to the ENFA")
+	}
+
+	first, second := compiled.NewRunner(), compiled.NewRunner()
+	if states := first.Step("a"); len(states) != 2 || states[0] != 1 || states[1] != 2 {
+		t.Errorf("Expect {1,2} after a, but get %v", states)
+	}
+	if states := second.States(); len(states) != 2 || states[0] != 0 || states[1] != 1 {
+		t.Errorf("Expect the second runner to stay at {0,1}, but get %v", states)
+	}
+	if !first.Run([]Symbol{"b"}) || second.Accepting() {
+		t.Errorf("Expect only the first runner to accept")
+	}
+	first.Reset()
+	if first.Accepting() {
+		t.Errorf("Expect the reset runner to start over")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if !compiled.Match([]Symbol{"b", "a", "a", "b"}) || compiled.Match([]Symbol{"a", "b", "a"}) {
+					t.Errorf("Expect concurrent matches to agree")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (suite *ENFATestSuite) TestRunner() {
+	suite.SetupTest()
+	t := suite.T()
+
+	nfa := suite.enfa
+	nfa.InsertState(1, false)
+	nfa.InsertState(2, true)
+	nfa.DefineTransition(0, "a", 1)
+
+	runner := nfa.Compile().NewRunner()
+	if ret := runner.Step("a"); len(ret) != 1 || ret[0] != 1 {
+		t.Errorf("Expect [1], but get %v", ret)
+	}
+	runner.Reset()
+	if ret := runner.States(); len(ret) != 1 || ret[0] != 0 {
+		t.Errorf("Expect the runner to start over at 0, but get %v", ret)
+	}
+
+	// The deprecated run keeps its snapshot until it is reinitialized
+	nfa.ProcessInput("a")
+	nfa.DefineTransition(1, "b", 2)
+	if nfa.ProcessInput("b"); nfa.CheckIfFinalState() {
+		t.Errorf("Expect the transition defined during the run to be ignored")
+	}
+
+	nfa.ReinitializeActiveStates()
+	nfa.ProcessInput("a")
+	if nfa.ProcessInput("b"); !nfa.CheckIfFinalState() {
+		t.Errorf("Expect a.b to reach the final state after reinitializing")
+	}
+}
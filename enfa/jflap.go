@@ -0,0 +1,94 @@
+package enfa
+
+import (
+	"fmt"
+	"github.com/jatin297/retoenfa/diagram"
+	. "github.com/jatin297/retoenfa/dto"
+	"github.com/jatin297/retoenfa/jflap"
+	"io"
+	"sort"
+	"unicode/utf8"
+)
+
+// ExportJFLAP writes the automaton as a JFLAP .jff file, placing states with the layered
+// layout of the diagram package. Epsilon moves become empty (λ) transitions.
+func (e *ENFA) ExportJFLAP(w io.Writer) error {
+	positions := diagram.Layout(e.Diagram())
+	states := e.States()
+	sort.Ints(states)
+
+	automaton := &jflap.Automaton{}
+	for _, state := range states {
+		automaton.States = append(automaton.States, jflap.State{
+			ID:      state,
+			Name:    fmt.Sprintf("q%d", state),
+			X:       positions[state].X,
+			Y:       positions[state].Y,
+			Initial: state == e.initialState,
+			Final:   e.IsFinalState(state),
+		})
+	}
+
+	symbols := append([]Symbol{Epsilon}, e.InputSymbols()...)
+	for _, state := range states {
+		for _, symbol := range symbols {
+			for _, next := range e.NextStates(state, symbol) {
+				automaton.Transitions = append(automaton.Transitions, jflap.Transition{From: state, To: next, Read: string(symbol)})
+			}
+		}
+	}
+	return jflap.Write(w, automaton)
+}
+
+// ImportJFLAP reads a finite automaton from a JFLAP .jff file. It needs exactly one initial
+// state and transitions reading at most one character; JFLAP state ids become the state numbers.
+func ImportJFLAP(r io.Reader) (*ENFA, error) {
+	automaton, err := jflap.Read(r)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[int]bool)
+	initialState := DeadState
+	for _, state := range automaton.States {
+		if state.ID < 0 {
+			return nil, fmt.Errorf("state id %d is negative", state.ID)
+		}
+		if states[state.ID] {
+			return nil, fmt.Errorf("state id %d is used twice", state.ID)
+		}
+		states[state.ID] = true
+		if state.Initial {
+			if initialState != DeadState {
+				return nil, fmt.Errorf("states %d and %d are both initial", initialState, state.ID)
+			}
+			initialState = state.ID
+		}
+	}
+	if initialState == DeadState {
+		return nil, fmt.Errorf("the automaton has no initial state")
+	}
+
+	var e *ENFA
+	for _, state := range automaton.States {
+		if state.ID == initialState {
+			e = CreateENFA(state.ID, state.Final)
+		}
+	}
+	for _, state := range automaton.States {
+		if state.ID != initialState {
+			e.InsertState(state.ID, state.Final)
+		}
+	}
+
+	for _, transition := range automaton.Transitions {
+		if !states[transition.From] || !states[transition.To] {
+			return nil, fmt.Errorf("transition from %d to %d refers to an undeclared state", transition.From, transition.To)
+		}
+		if utf8.RuneCountInString(transition.Read) > 1 {
+			return nil, fmt.Errorf("transition from %d to %d reads %q, only single characters are supported", transition.From, transition.To, transition.Read)
+		}
+		e.DefineTransition(transition.From, Symbol(transition.Read), transition.To)
+	}
+	return e, nil
+}
@@ -0,0 +1,150 @@
+package enfa
+
+import (
+	"encoding/json"
+	"fmt"
+	. "github.com/jatin297/retoenfa/dto"
+	"sort"
+	"unicode/utf8"
+)
+
+// jsonENFA is the wire form of an ENFA:
+//
+//	{
+//	  "states": [0, 1, 2],
+//	  "initial_state": 0,
+//	  "final_states": [2],
+//	  "alphabet": ["a", "b"],
+//	  "transitions": [
+//	    {"from": 0, "symbol": "", "to": [1]},
+//	    {"from": 1, "symbol": "a", "to": [1, 2]}
+//	  ]
+//	}
+//
+// States keep their order, the alphabet lists every declared input symbol even when unused,
+// and an empty symbol is an epsilon move. Symbols are single characters, transitions are
+// sorted by source and symbol, and destinations ascend.
+type jsonENFA struct {
+	States       []int            `json:"states"`
+	InitialState int              `json:"initial_state"`
+	FinalStates  []int            `json:"final_states"`
+	Alphabet     []Symbol         `json:"alphabet"`
+	Transitions  []jsonTransition `json:"transitions"`
+}
+
+type jsonTransition struct {
+	From   int    `json:"from"`
+	Symbol Symbol `json:"symbol"`
+	To     []int  `json:"to"`
+}
+
+// MarshalJSON encodes the automaton in the schema documented on jsonENFA.
+func (e *ENFA) MarshalJSON() ([]byte, error) {
+	encoded := jsonENFA{
+		States:       e.States(),
+		InitialState: e.initialState,
+		FinalStates:  append([]int{}, e.finalStates...),
+		Alphabet:     append([]Symbol{}, e.InputSymbols()...),
+		Transitions:  []jsonTransition{},
+	}
+	sort.Ints(encoded.FinalStates)
+
+	symbols := append([]Symbol{Epsilon}, e.InputSymbols()...)
+	states := e.States()
+	sort.Ints(states)
+	for _, state := range states {
+		for _, symbol := range symbols {
+			if next := e.NextStates(state, symbol); len(next) > 0 {
+				encoded.Transitions = append(encoded.Transitions, jsonTransition{From: state, Symbol: symbol, To: next})
+			}
+		}
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON replaces the automaton with one decoded from the schema documented on
+// jsonENFA, rejecting references to undeclared states or symbols.
+func (e *ENFA) UnmarshalJSON(data []byte) error {
+	var decoded jsonENFA
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	states := make(map[int]bool)
+	for _, state := range decoded.States {
+		if state == DeadState {
+			return fmt.Errorf("state %d is reserved for the dead state", DeadState)
+		}
+		if states[state] {
+			return fmt.Errorf("state %d is listed twice", state)
+		}
+		states[state] = true
+	}
+	if !states[decoded.InitialState] {
+		return fmt.Errorf("initial state %d is not listed in states", decoded.InitialState)
+	}
+	final := make(map[int]bool)
+	for _, state := range decoded.FinalStates {
+		if !states[state] {
+			return fmt.Errorf("final state %d is not listed in states", state)
+		}
+		final[state] = true
+	}
+	alphabet := make(map[Symbol]bool)
+	for _, symbol := range decoded.Alphabet {
+		if utf8.RuneCountInString(string(symbol)) != 1 {
+			return fmt.Errorf("alphabet symbol %q is not a single character", symbol)
+		}
+		alphabet[symbol] = true
+	}
+
+	result := CreateENFA(decoded.InitialState, final[decoded.InitialState])
+	for _, state := range decoded.States {
+		if state != decoded.InitialState {
+			result.InsertState(state, final[state])
+		}
+	}
+	for _, symbol := range decoded.Alphabet {
+		result.AddInputSymbol(symbol)
+	}
+	for _, transition := range decoded.Transitions {
+		if !states[transition.From] {
+			return fmt.Errorf("transition from undeclared state %d", transition.From)
+		}
+		if !transition.Symbol.IsEpsilon() && !alphabet[transition.Symbol] {
+			return fmt.Errorf("transition from %d on %q, which is not in the alphabet", transition.From, transition.Symbol)
+		}
+		for _, destination := range transition.To {
+			if !states[destination] {
+				return fmt.Errorf("transition from %d on %s to undeclared state %d", transition.From, transition.Symbol, destination)
+			}
+		}
+		result.DefineTransition(transition.From, transition.Symbol, transition.To...)
+	}
+
+	*e = *result
+	return nil
+}
+
+// IsDeterministic reports whether the automaton has no epsilon moves and at most one
+// destination for every state and symbol.
+func (e *ENFA) IsDeterministic() bool {
+	for key, destinations := range e.transitions {
+		if len(destinations) == 0 {
+			continue
+		}
+		if key.InputSymbol.IsEpsilon() || len(destinations) > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// TransitionCount returns the number of edges, counting each destination separately.
+func (e *ENFA) TransitionCount() int {
+	count := 0
+	for _, destinations := range e.transitions {
+		count += len(destinations)
+	}
+	return count
+}
@@ -0,0 +1,112 @@
+package enfa
+
+import (
+	. "github.com/jatin297/retoenfa/dto"
+	"sort"
+)
+
+// Compiled is a read-only snapshot of an ENFA prepared for matching. Nothing modifies it after
+// Compile returns, so one Compiled can be cached and shared by any number of goroutines, each
+// matching through its own Runner.
+type Compiled struct {
+	start       StateSet
+	finalStates map[int]bool
+	moves       map[TransitionKey][]int
+}
+
+// Compile snapshots the ENFA for matching, resolving epsilon closures ahead of time. Later
+// changes to the ENFA do not affect the result.
+func (e *ENFA) Compile() *Compiled {
+	compiled := &Compiled{
+		start:       e.epsilonClosure(StateSet{e.initialState: true}),
+		finalStates: make(map[int]bool),
+		moves:       make(map[TransitionKey][]int),
+	}
+	for _, state := range e.finalStates {
+		compiled.finalStates[state] = true
+	}
+	for _, state := range e.states {
+		for symbol := range e.inputSymbols {
+			if symbol.IsEpsilon() {
+				continue
+			}
+			reached := e.step(StateSet{state: true}, symbol)
+			if len(reached) == 0 {
+				continue
+			}
+			key := TransitionKey{SourceState: state, InputSymbol: symbol}
+			for dest := range reached {
+				compiled.moves[key] = append(compiled.moves[key], dest)
+			}
+		}
+	}
+	return compiled
+}
+
+// NewRunner starts a run at the initial state.
+func (c *Compiled) NewRunner() *Runner {
+	runner := &Runner{automaton: c}
+	runner.Reset()
+	return runner
+}
+
+// Match reports whether the automaton accepts the sequence of input symbols.
+func (c *Compiled) Match(inputs []Symbol) bool {
+	return c.NewRunner().Run(inputs)
+}
+
+// Runner holds the state of one run over a Compiled automaton. A Runner is cheap to create
+// and must not be shared between goroutines.
+type Runner struct {
+	automaton *Compiled
+	active    StateSet
+}
+
+// Reset puts the run back at the initial state.
+func (r *Runner) Reset() {
+	r.active = make(StateSet, len(r.automaton.start))
+	for state := range r.automaton.start {
+		r.active[state] = true
+	}
+}
+
+// Step consumes one input symbol and returns the sorted active states, closed under epsilon
+// transitions.
+func (r *Runner) Step(input Symbol) []int {
+	next := make(StateSet)
+	for state := range r.active {
+		for _, dest := range r.automaton.moves[TransitionKey{SourceState: state, InputSymbol: input}] {
+			next[dest] = true
+		}
+	}
+	r.active = next
+	return r.States()
+}
+
+// Run consumes the input symbols in order and reports whether the run ends accepting.
+func (r *Runner) Run(inputs []Symbol) bool {
+	for _, inputSymbol := range inputs {
+		r.Step(inputSymbol)
+	}
+	return r.Accepting()
+}
+
+// States returns the sorted active states.
+func (r *Runner) States() []int {
+	states := make([]int, 0, len(r.active))
+	for state := range r.active {
+		states = append(states, state)
+	}
+	sort.Ints(states)
+	return states
+}
+
+// Accepting reports whether any active state is final.
+func (r *Runner) Accepting() bool {
+	for state := range r.active {
+		if r.automaton.finalStates[state] {
+			return true
+		}
+	}
+	return false
+}
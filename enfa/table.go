@@ -0,0 +1,78 @@
+package enfa
+
+import (
+	"fmt"
+	. "github.com/jatin297/retoenfa/dto"
+	"unicode/utf8"
+)
+
+// FromTransitionTable rebuilds an automaton from a table as GenerateFormattedTransitionTable
+// emits it. A DeadState row, as in DFA tables, is dropped together with the edges into it,
+// which leaves the language unchanged.
+func FromTransitionTable(table Table) (*ENFA, error) {
+	symbols := make([]Symbol, len(table.Symbols))
+	for i, column := range table.Symbols {
+		symbol, err := parseColumn(column)
+		if err != nil {
+			return nil, err
+		}
+		symbols[i] = symbol
+	}
+
+	rows := make(map[int]bool)
+	for i, row := range table.Rows {
+		if rows[row.State] {
+			return nil, fmt.Errorf("row %d: state %d is listed twice", i, row.State)
+		}
+		if len(row.Next) != len(symbols) {
+			return nil, fmt.Errorf("row %d: expected %d columns, found %d", i, len(symbols), len(row.Next))
+		}
+		rows[row.State] = true
+	}
+	if !rows[table.InitialState] || table.InitialState == DeadState {
+		return nil, fmt.Errorf("initial state %d has no row", table.InitialState)
+	}
+
+	var e *ENFA
+	for _, row := range table.Rows {
+		if row.State == table.InitialState {
+			e = CreateENFA(row.State, row.Final)
+		}
+	}
+	for _, row := range table.Rows {
+		if row.State != table.InitialState && row.State != DeadState {
+			e.InsertState(row.State, row.Final)
+		}
+	}
+
+	for _, symbol := range symbols {
+		e.AddInputSymbol(symbol)
+	}
+	for _, row := range table.Rows {
+		if row.State == DeadState {
+			continue
+		}
+		for column, destinations := range row.Next {
+			for _, destination := range destinations {
+				if !rows[destination] {
+					return nil, fmt.Errorf("state %d on %s: destination %d has no row", row.State, table.Symbols[column], destination)
+				}
+				if destination != DeadState {
+					e.DefineTransition(row.State, symbols[column], destination)
+				}
+			}
+		}
+	}
+	return e, nil
+}
+
+// parseColumn reads a column header back into the symbol it was printed from.
+func parseColumn(column string) (Symbol, error) {
+	if column == Epsilon.String() {
+		return Epsilon, nil
+	}
+	if utf8.RuneCountInString(column) != 1 {
+		return Epsilon, fmt.Errorf("column %q is not a single symbol", column)
+	}
+	return Symbol(column), nil
+}
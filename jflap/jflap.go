@@ -0,0 +1,119 @@
+// Package jflap reads and writes finite automata in the XML .jff format of JFLAP.
+package jflap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// Automaton is a JFLAP finite automaton. An empty Read is an epsilon (λ) transition.
+type Automaton struct {
+	States      []State
+	Transitions []Transition
+}
+
+type State struct {
+	ID      int
+	Name    string
+	X       float64
+	Y       float64
+	Initial bool
+	Final   bool
+}
+
+type Transition struct {
+	From int
+	To   int
+	Read string
+}
+
+type jffStructure struct {
+	XMLName   xml.Name      `xml:"structure"`
+	Type      string        `xml:"type"`
+	Automaton *jffAutomaton `xml:"automaton"`
+	// Files written before JFLAP 7 keep states and transitions directly under the structure
+	States      []jffState      `xml:"state"`
+	Transitions []jffTransition `xml:"transition"`
+}
+
+type jffAutomaton struct {
+	States      []jffState      `xml:"state"`
+	Transitions []jffTransition `xml:"transition"`
+}
+
+type jffState struct {
+	ID      int       `xml:"id,attr"`
+	Name    string    `xml:"name,attr"`
+	X       float64   `xml:"x"`
+	Y       float64   `xml:"y"`
+	Initial *struct{} `xml:"initial"`
+	Final   *struct{} `xml:"final"`
+}
+
+type jffTransition struct {
+	From int    `xml:"from"`
+	To   int    `xml:"to"`
+	Read string `xml:"read"`
+}
+
+// Write encodes the automaton as a .jff document of type fa.
+func Write(w io.Writer, a *Automaton) error {
+	automaton := &jffAutomaton{}
+	for _, state := range a.States {
+		encoded := jffState{ID: state.ID, Name: state.Name, X: state.X, Y: state.Y}
+		if state.Initial {
+			encoded.Initial = &struct{}{}
+		}
+		if state.Final {
+			encoded.Final = &struct{}{}
+		}
+		automaton.States = append(automaton.States, encoded)
+	}
+	for _, transition := range a.Transitions {
+		automaton.Transitions = append(automaton.Transitions, jffTransition(transition))
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "\t")
+	if err := encoder.Encode(jffStructure{Type: "fa", Automaton: automaton}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Read decodes a .jff document, which must describe a finite automaton.
+func Read(r io.Reader) (*Automaton, error) {
+	var decoded jffStructure
+	if err := xml.NewDecoder(r).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("invalid jff document: %w", err)
+	}
+	if decoded.Type != "fa" {
+		return nil, fmt.Errorf("unsupported jff type %q, expected fa", decoded.Type)
+	}
+
+	states, transitions := decoded.States, decoded.Transitions
+	if decoded.Automaton != nil {
+		states, transitions = decoded.Automaton.States, decoded.Automaton.Transitions
+	}
+
+	a := &Automaton{}
+	for _, state := range states {
+		a.States = append(a.States, State{
+			ID:      state.ID,
+			Name:    state.Name,
+			X:       state.X,
+			Y:       state.Y,
+			Initial: state.Initial != nil,
+			Final:   state.Final != nil,
+		})
+	}
+	for _, transition := range transitions {
+		a.Transitions = append(a.Transitions, Transition(transition))
+	}
+	return a, nil
+}
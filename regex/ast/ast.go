@@ -0,0 +1,177 @@
+package ast
+
+import (
+	"github.com/jatin297/retoenfa/dto"
+	"sort"
+)
+
+// Dialect selects the concrete syntax an expression is written in.
+type Dialect int
+
+const (
+	// Classic is the textbook dialect: + is union, . is explicit concatenation, * is the
+	// Kleene star and e stands for epsilon.
+	Classic Dialect = iota
+	// Conventional follows everyday regex syntax: juxtaposition concatenates, | is union,
+	// and *, +, ?, {m}, {m,} and {m,n} are postfix repetitions.
+	Conventional
+)
+
+// AST is the parse tree of a regular expression together with the dialect it was written in.
+type AST struct {
+	Root    Node
+	Dialect Dialect
+}
+
+// String prints the expression back in its own dialect.
+func (a *AST) String() string {
+	return Print(a.Root, a.Dialect)
+}
+
+// Node is a subexpression. The concrete types are Literal, Epsilon, Empty, Class, Concat,
+// Union, Star and Repeat.
+type Node interface {
+	// String prints the node in the classic dialect.
+	String() string
+	isNode()
+}
+
+// Literal matches a single symbol.
+type Literal struct {
+	Symbol dto.Symbol
+}
+
+// Epsilon matches the empty string.
+type Epsilon struct{}
+
+// Empty matches nothing at all.
+type Empty struct{}
+
+// Class matches any one of its members, or any alphabet symbol outside them when negated.
+// A negated class without members is the wildcard.
+type Class struct {
+	Members []dto.Symbol
+	Negated bool
+}
+
+// Concat matches its items one after the other.
+type Concat struct {
+	Items []Node
+}
+
+// Union matches any one of its items.
+type Union struct {
+	Items []Node
+}
+
+// Star matches any number of repetitions of Sub, including none.
+type Star struct {
+	Sub Node
+}
+
+// Repeat matches Sub between Min and Max times, Max is -1 when unbounded.
+type Repeat struct {
+	Sub Node
+	Min int
+	Max int
+}
+
+func (Literal) isNode() {}
+func (Epsilon) isNode() {}
+func (Empty) isNode()   {}
+func (Class) isNode()   {}
+func (Concat) isNode()  {}
+func (Union) isNode()   {}
+func (Star) isNode()    {}
+func (Repeat) isNode()  {}
+
+func (n Literal) String() string { return Print(n, Classic) }
+func (n Epsilon) String() string { return Print(n, Classic) }
+func (n Empty) String() string   { return Print(n, Classic) }
+func (n Class) String() string   { return Print(n, Classic) }
+func (n Concat) String() string  { return Print(n, Classic) }
+func (n Union) String() string   { return Print(n, Classic) }
+func (n Star) String() string    { return Print(n, Classic) }
+func (n Repeat) String() string  { return Print(n, Classic) }
+
+// Matching resolves the class against the alphabet into the symbols it accepts.
+func (n Class) Matching(alphabet []dto.Symbol) []dto.Symbol {
+	if !n.Negated {
+		return n.Members
+	}
+
+	excluded := make(map[dto.Symbol]bool)
+	for _, member := range n.Members {
+		excluded[member] = true
+	}
+	var symbols []dto.Symbol
+	for _, symbol := range alphabet {
+		if !excluded[symbol] {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
+
+// Expand rewrites the repetition with the basic operators, e.g. a{2,3} as a.a.(a+e).
+func (n Repeat) Expand() Node {
+	var items []Node
+	for count := 0; count < n.Min; count++ {
+		items = append(items, n.Sub)
+	}
+	if n.Max < 0 {
+		items = append(items, Star{Sub: n.Sub})
+	}
+	for count := n.Min; count < n.Max; count++ {
+		items = append(items, Union{Items: []Node{n.Sub, Epsilon{}}})
+	}
+
+	switch len(items) {
+	case 0:
+		return Epsilon{}
+	case 1:
+		return items[0]
+	}
+	return Concat{Items: items}
+}
+
+// Walk calls visit for the node and every node below it, parents before children.
+func Walk(n Node, visit func(Node)) {
+	visit(n)
+	switch n := n.(type) {
+	case Concat:
+		for _, item := range n.Items {
+			Walk(item, visit)
+		}
+	case Union:
+		for _, item := range n.Items {
+			Walk(item, visit)
+		}
+	case Star:
+		Walk(n.Sub, visit)
+	case Repeat:
+		Walk(n.Sub, visit)
+	}
+}
+
+// Symbols returns the sorted characters the expression names, class members included.
+func Symbols(n Node) []dto.Symbol {
+	seen := make(map[dto.Symbol]bool)
+	Walk(n, func(current Node) {
+		switch current := current.(type) {
+		case Literal:
+			seen[current.Symbol] = true
+		case Class:
+			for _, member := range current.Members {
+				seen[member] = true
+			}
+		}
+	})
+
+	symbols := []dto.Symbol{}
+	for symbol := range seen {
+		symbols = append(symbols, symbol)
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i] < symbols[j] })
+	return symbols
+}
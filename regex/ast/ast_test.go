@@ -0,0 +1,67 @@
+package ast
+
+import (
+	"encoding/json"
+	"github.com/jatin297/retoenfa/dto"
+	"reflect"
+	"testing"
+)
+
+func sample() Node {
+	return Concat{Items: []Node{
+		Star{Sub: Union{Items: []Node{Literal{Symbol: "a"}, Literal{Symbol: "+"}, Epsilon{}}}},
+		Repeat{Sub: Class{Members: []dto.Symbol{"0", "1", "2", "3"}}, Min: 2, Max: -1},
+		Class{Negated: true},
+		Empty{},
+	}}
+}
+
+func TestPrint(t *testing.T) {
+	if printed := Print(sample(), Conventional); printed != `(a|\+|)*[0-3]{2,}.[]` {
+		t.Errorf("Expect the conventional form, but get %q", printed)
+	}
+	if printed := Print(sample(), Classic); printed != `(a+\++e)*.([0-3].[0-3].[0-3]*).[^].[]` {
+		t.Errorf("Expect the classic form, but get %q", printed)
+	}
+
+	// Nested nodes of the same kind keep their grouping
+	nested := Union{Items: []Node{Union{Items: []Node{Literal{Symbol: "a"}, Literal{Symbol: "b"}}}, Literal{Symbol: "c"}}}
+	if printed := nested.String(); printed != "(a+b)+c" {
+		t.Errorf("Expect (a+b)+c, but get %q", printed)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	tree := &AST{Root: sample(), Dialect: Conventional}
+	data, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded AST
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&decoded, tree) {
+		t.Errorf("Expect the tree back from %s, but get %#v", data, decoded)
+	}
+
+	node, err := UnmarshalNode([]byte(`{"type":"repeat","sub":{"type":"literal","symbol":"x"},"min":1,"max":3}`))
+	if err != nil || !reflect.DeepEqual(node, Repeat{Sub: Literal{Symbol: "x"}, Min: 1, Max: 3}) {
+		t.Errorf("Expect x{1,3}, but get %v (err=%v)", node, err)
+	}
+
+	for _, invalid := range []string{`{"type":"literal"}`, `{"type":"star"}`, `{"type":"repeat","sub":{"type":"epsilon"},"min":3,"max":1}`, `{"type":"plus"}`} {
+		if _, err := UnmarshalNode([]byte(invalid)); err == nil {
+			t.Errorf("Expect %s to be rejected", invalid)
+		}
+	}
+}
+
+func TestSymbols(t *testing.T) {
+	symbols := Symbols(sample())
+	expected := []dto.Symbol{"+", "0", "1", "2", "3", "a"}
+	if !reflect.DeepEqual(symbols, expected) {
+		t.Errorf("Expect %v, but get %v", expected, symbols)
+	}
+}
@@ -0,0 +1,172 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jatin297/retoenfa/dto"
+)
+
+// String names the dialect the way the API and the JSON encoding spell it.
+func (d Dialect) String() string {
+	if d == Conventional {
+		return "conventional"
+	}
+	return "classic"
+}
+
+// ParseDialect resolves a dialect name. The empty name is Classic.
+func ParseDialect(name string) (Dialect, error) {
+	switch name {
+	case "", "classic":
+		return Classic, nil
+	case "conventional":
+		return Conventional, nil
+	}
+	return Classic, fmt.Errorf("unknown regex dialect: %s", name)
+}
+
+// jsonNode is the wire form of every node type, told apart by Type:
+//
+//	{"type": "literal", "symbol": "a"}
+//	{"type": "epsilon"}
+//	{"type": "empty"}
+//	{"type": "class", "members": ["0", "1"], "negated": true}
+//	{"type": "concat", "items": [...]}
+//	{"type": "union", "items": [...]}
+//	{"type": "star", "sub": {...}}
+//	{"type": "repeat", "sub": {...}, "min": 2, "max": -1}
+type jsonNode struct {
+	Type    string       `json:"type"`
+	Symbol  dto.Symbol   `json:"symbol,omitempty"`
+	Members []dto.Symbol `json:"members,omitempty"`
+	Negated bool         `json:"negated,omitempty"`
+	Items   []jsonNode   `json:"items,omitempty"`
+	Sub     *jsonNode    `json:"sub,omitempty"`
+	Min     *int         `json:"min,omitempty"`
+	Max     *int         `json:"max,omitempty"`
+}
+
+type jsonAST struct {
+	Dialect string   `json:"dialect"`
+	Root    jsonNode `json:"root"`
+	Source  string   `json:"source"`
+}
+
+// MarshalJSON encodes the tree with its dialect and the expression printed back in it.
+func (a *AST) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonAST{Dialect: a.Dialect.String(), Root: encode(a.Root), Source: a.String()})
+}
+
+// UnmarshalJSON decodes a tree written by MarshalJSON. The source field is informative only.
+func (a *AST) UnmarshalJSON(data []byte) error {
+	var decoded jsonAST
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	dialect, err := ParseDialect(decoded.Dialect)
+	if err != nil {
+		return err
+	}
+	root, err := decode(decoded.Root)
+	if err != nil {
+		return err
+	}
+	a.Root, a.Dialect = root, dialect
+	return nil
+}
+
+// UnmarshalNode decodes a single node written by json.Marshal.
+func UnmarshalNode(data []byte) (Node, error) {
+	var decoded jsonNode
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return decode(decoded)
+}
+
+func (n Literal) MarshalJSON() ([]byte, error) { return json.Marshal(encode(n)) }
+func (n Epsilon) MarshalJSON() ([]byte, error) { return json.Marshal(encode(n)) }
+func (n Empty) MarshalJSON() ([]byte, error)   { return json.Marshal(encode(n)) }
+func (n Class) MarshalJSON() ([]byte, error)   { return json.Marshal(encode(n)) }
+func (n Concat) MarshalJSON() ([]byte, error)  { return json.Marshal(encode(n)) }
+func (n Union) MarshalJSON() ([]byte, error)   { return json.Marshal(encode(n)) }
+func (n Star) MarshalJSON() ([]byte, error)    { return json.Marshal(encode(n)) }
+func (n Repeat) MarshalJSON() ([]byte, error)  { return json.Marshal(encode(n)) }
+
+func encode(n Node) jsonNode {
+	switch n := n.(type) {
+	case Literal:
+		return jsonNode{Type: "literal", Symbol: n.Symbol}
+	case Epsilon:
+		return jsonNode{Type: "epsilon"}
+	case Empty:
+		return jsonNode{Type: "empty"}
+	case Class:
+		return jsonNode{Type: "class", Members: n.Members, Negated: n.Negated}
+	case Concat:
+		return jsonNode{Type: "concat", Items: encodeAll(n.Items)}
+	case Union:
+		return jsonNode{Type: "union", Items: encodeAll(n.Items)}
+	case Star:
+		sub := encode(n.Sub)
+		return jsonNode{Type: "star", Sub: &sub}
+	case Repeat:
+		sub := encode(n.Sub)
+		min, max := n.Min, n.Max
+		return jsonNode{Type: "repeat", Sub: &sub, Min: &min, Max: &max}
+	}
+	panic(fmt.Sprintf("ast: unknown node type %T", n))
+}
+
+func encodeAll(items []Node) []jsonNode {
+	encoded := make([]jsonNode, len(items))
+	for i, item := range items {
+		encoded[i] = encode(item)
+	}
+	return encoded
+}
+
+func decode(j jsonNode) (Node, error) {
+	switch j.Type {
+	case "literal":
+		if j.Symbol.IsEpsilon() {
+			return nil, fmt.Errorf("literal without symbol")
+		}
+		return Literal{Symbol: j.Symbol}, nil
+	case "epsilon":
+		return Epsilon{}, nil
+	case "empty":
+		return Empty{}, nil
+	case "class":
+		return Class{Members: j.Members, Negated: j.Negated}, nil
+	case "concat", "union":
+		items := make([]Node, len(j.Items))
+		for i, item := range j.Items {
+			decoded, err := decode(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = decoded
+		}
+		if j.Type == "concat" {
+			return Concat{Items: items}, nil
+		}
+		return Union{Items: items}, nil
+	case "star", "repeat":
+		if j.Sub == nil {
+			return nil, fmt.Errorf("%s without sub", j.Type)
+		}
+		sub, err := decode(*j.Sub)
+		if err != nil {
+			return nil, err
+		}
+		if j.Type == "star" {
+			return Star{Sub: sub}, nil
+		}
+		if j.Min == nil || j.Max == nil || *j.Min < 0 || (*j.Max >= 0 && *j.Max < *j.Min) {
+			return nil, fmt.Errorf("repeat needs 0 <= min <= max, or max -1 for unbounded")
+		}
+		return Repeat{Sub: sub, Min: *j.Min, Max: *j.Max}, nil
+	}
+	return nil, fmt.Errorf("unknown node type: %q", j.Type)
+}
@@ -0,0 +1,187 @@
+package ast
+
+import (
+	"fmt"
+	"github.com/jatin297/retoenfa/dto"
+	"strings"
+)
+
+// Binding strength of each construct, loosest first.
+const (
+	precedenceUnion = iota + 1
+	precedenceConcat
+	precedencePostfix
+	precedenceAtom
+)
+
+// Print renders the node in the given dialect, adding only the parentheses the grammar needs,
+// so that parsing the result in the same dialect gives back the same tree. The classic dialect
+// has no counted repetition and prints Repeat expanded.
+func Print(n Node, dialect Dialect) string {
+	p := printer{dialect: dialect}
+	return p.print(n)
+}
+
+type printer struct {
+	dialect Dialect
+}
+
+func (p printer) print(n Node) string {
+	switch n := n.(type) {
+	case Literal:
+		return p.escape(n.Symbol)
+	case Epsilon:
+		if p.dialect == Conventional {
+			return "()"
+		}
+		return "e"
+	case Empty:
+		return "[]"
+	case Class:
+		return p.class(n)
+	case Concat:
+		separator := "."
+		if p.dialect == Conventional {
+			separator = ""
+		}
+		return p.join(n.Items, separator, precedenceConcat)
+	case Union:
+		separator := "+"
+		if p.dialect == Conventional {
+			separator = "|"
+		}
+		return p.join(n.Items, separator, precedenceUnion)
+	case Star:
+		return p.operand(n.Sub, precedencePostfix) + "*"
+	case Repeat:
+		if p.dialect == Classic {
+			return p.print(n.Expand())
+		}
+		return p.operand(n.Sub, precedencePostfix) + repeatSuffix(n)
+	}
+	panic(fmt.Sprintf("ast: unknown node type %T", n))
+}
+
+// join prints the items of an n-ary node. Items of the same kind keep their parentheses so
+// the grouping survives a round trip.
+func (p printer) join(items []Node, separator string, precedence int) string {
+	switch len(items) {
+	case 0:
+		return p.print(Epsilon{})
+	case 1:
+		return p.print(items[0])
+	}
+
+	parts := make([]string, len(items))
+	for i, item := range items {
+		// An empty alternative already reads as epsilon in the conventional dialect
+		if _, ok := item.(Epsilon); ok && p.dialect == Conventional && precedence == precedenceUnion {
+			continue
+		}
+		parts[i] = p.operand(item, precedence+1)
+	}
+	return strings.Join(parts, separator)
+}
+
+// operand prints a child, wrapping it in parentheses when it binds looser than required.
+func (p printer) operand(n Node, required int) string {
+	if p.precedence(n) < required {
+		return "(" + p.print(n) + ")"
+	}
+	return p.print(n)
+}
+
+func (p printer) precedence(n Node) int {
+	switch n := n.(type) {
+	case Concat:
+		if len(n.Items) == 1 {
+			return p.precedence(n.Items[0])
+		}
+		if len(n.Items) > 1 {
+			return precedenceConcat
+		}
+	case Union:
+		if len(n.Items) == 1 {
+			return p.precedence(n.Items[0])
+		}
+		if len(n.Items) > 1 {
+			return precedenceUnion
+		}
+	case Star:
+		return precedencePostfix
+	case Repeat:
+		if p.dialect == Classic {
+			return p.precedence(n.Expand())
+		}
+		return precedencePostfix
+	}
+	return precedenceAtom
+}
+
+func repeatSuffix(n Repeat) string {
+	switch {
+	case n.Min == 1 && n.Max < 0:
+		return "+"
+	case n.Min == 0 && n.Max == 1:
+		return "?"
+	case n.Max < 0:
+		return fmt.Sprintf("{%d,}", n.Min)
+	case n.Min == n.Max:
+		return fmt.Sprintf("{%d}", n.Min)
+	}
+	return fmt.Sprintf("{%d,%d}", n.Min, n.Max)
+}
+
+// escape prints a literal, escaping it when the dialect would read it as an operator.
+func (p printer) escape(symbol dto.Symbol) string {
+	operators := `+.*()e[\`
+	if p.dialect == Conventional {
+		operators = `|*+?.()[{\`
+	}
+	if len(symbol) == 1 && strings.Contains(operators, string(symbol)) {
+		return `\` + string(symbol)
+	}
+	return string(symbol)
+}
+
+func (p printer) class(n Class) string {
+	if n.Negated && len(n.Members) == 0 && p.dialect == Conventional {
+		return "."
+	}
+
+	var builder strings.Builder
+	builder.WriteString("[")
+	if n.Negated {
+		builder.WriteString("^")
+	}
+
+	// Runs of three or more consecutive characters are printed as ranges
+	for start := 0; start < len(n.Members); {
+		end := start
+		for end+1 < len(n.Members) && consecutive(n.Members[end], n.Members[end+1]) {
+			end++
+		}
+		if end-start >= 2 {
+			builder.WriteString(escapeInClass(n.Members[start]) + "-" + escapeInClass(n.Members[end]))
+		} else {
+			for _, member := range n.Members[start : end+1] {
+				builder.WriteString(escapeInClass(member))
+			}
+		}
+		start = end + 1
+	}
+	builder.WriteString("]")
+	return builder.String()
+}
+
+func consecutive(first, second dto.Symbol) bool {
+	firstRunes, secondRunes := []rune(string(first)), []rune(string(second))
+	return len(firstRunes) == 1 && len(secondRunes) == 1 && secondRunes[0] == firstRunes[0]+1
+}
+
+func escapeInClass(symbol dto.Symbol) string {
+	if strings.Contains(`]\^-`, string(symbol)) && len(symbol) == 1 {
+		return `\` + string(symbol)
+	}
+	return string(symbol)
+}
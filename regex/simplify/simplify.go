@@ -0,0 +1,366 @@
+// Package simplify rewrites regular expressions with Kleene algebra identities, keeping
+// the language unchanged while removing redundancy.
+package simplify
+
+import (
+	"github.com/jatin297/retoenfa/regex/ast"
+	"sort"
+)
+
+// Step records one rewrite: the rule applied and the subexpression before and after it.
+type Step struct {
+	Rule   string
+	Before ast.Node
+	After  ast.Node
+}
+
+// rule rewrites a node whose children are already simplified, reporting whether it applied.
+type rule struct {
+	name  string
+	apply func(ast.Node) (ast.Node, bool)
+}
+
+// rules are tried in order at every node until none applies. Rule names use the classic dialect.
+var rules = []rule{
+	{"(r) = r", unwrap},
+	{"(r+s)+t = r+s+t", flattenUnion},
+	{"(r.s).t = r.s.t", flattenConcat},
+	{"∅.r = ∅", emptyConcat},
+	{"∅+r = r", emptyUnion},
+	{"e.r = r", epsilonConcat},
+	{"r+r = r", idempotentUnion},
+	{"e+r.r* = r*", plusToStar},
+	{"e+r = r if r matches e", nullableUnion},
+	{"r+s = s+r", sortUnion},
+	{"r*.r* = r*", adjacentStars},
+	{"(r*)* = r*", starStar},
+	{"e* = ∅* = e", trivialStar},
+	{"(e+r)* = r*", starEpsilonUnion},
+	{"r{m,n} in basic operators", trivialRepeat},
+}
+
+// Simplify rewrites the expression bottom up until no rule applies and returns the result
+// together with the rewrites in the order they were made.
+func Simplify(n ast.Node) (ast.Node, []Step) {
+	s := &simplifier{}
+	return s.rewrite(n), s.steps
+}
+
+type simplifier struct {
+	steps []Step
+}
+
+func (s *simplifier) rewrite(n ast.Node) ast.Node {
+	switch current := n.(type) {
+	case ast.Concat:
+		n = ast.Concat{Items: s.rewriteAll(current.Items)}
+	case ast.Union:
+		n = ast.Union{Items: s.rewriteAll(current.Items)}
+	case ast.Star:
+		n = ast.Star{Sub: s.rewrite(current.Sub)}
+	case ast.Repeat:
+		n = ast.Repeat{Sub: s.rewrite(current.Sub), Min: current.Min, Max: current.Max}
+	}
+
+	for _, r := range rules {
+		if result, ok := r.apply(n); ok {
+			s.steps = append(s.steps, Step{Rule: r.name, Before: n, After: result})
+			return s.rewrite(result)
+		}
+	}
+	return n
+}
+
+func (s *simplifier) rewriteAll(items []ast.Node) []ast.Node {
+	rewritten := make([]ast.Node, len(items))
+	for i, item := range items {
+		rewritten[i] = s.rewrite(item)
+	}
+	return rewritten
+}
+
+// Nullable reports whether the expression matches the empty string.
+func Nullable(n ast.Node) bool {
+	switch n := n.(type) {
+	case ast.Epsilon, ast.Star:
+		return true
+	case ast.Concat:
+		for _, item := range n.Items {
+			if !Nullable(item) {
+				return false
+			}
+		}
+		return true
+	case ast.Union:
+		for _, item := range n.Items {
+			if Nullable(item) {
+				return true
+			}
+		}
+	case ast.Repeat:
+		return n.Min == 0 || Nullable(n.Sub)
+	}
+	return false
+}
+
+func key(n ast.Node) string {
+	return ast.Print(n, ast.Conventional)
+}
+
+func unwrap(n ast.Node) (ast.Node, bool) {
+	switch n := n.(type) {
+	case ast.Concat:
+		switch len(n.Items) {
+		case 0:
+			return ast.Epsilon{}, true
+		case 1:
+			return n.Items[0], true
+		}
+	case ast.Union:
+		switch len(n.Items) {
+		case 0:
+			return ast.Empty{}, true
+		case 1:
+			return n.Items[0], true
+		}
+	}
+	return n, false
+}
+
+func flattenUnion(n ast.Node) (ast.Node, bool) {
+	union, ok := n.(ast.Union)
+	if !ok {
+		return n, false
+	}
+	var items []ast.Node
+	changed := false
+	for _, item := range union.Items {
+		if inner, ok := item.(ast.Union); ok {
+			items = append(items, inner.Items...)
+			changed = true
+		} else {
+			items = append(items, item)
+		}
+	}
+	return ast.Union{Items: items}, changed
+}
+
+func flattenConcat(n ast.Node) (ast.Node, bool) {
+	concat, ok := n.(ast.Concat)
+	if !ok {
+		return n, false
+	}
+	var items []ast.Node
+	changed := false
+	for _, item := range concat.Items {
+		if inner, ok := item.(ast.Concat); ok {
+			items = append(items, inner.Items...)
+			changed = true
+		} else {
+			items = append(items, item)
+		}
+	}
+	return ast.Concat{Items: items}, changed
+}
+
+func emptyConcat(n ast.Node) (ast.Node, bool) {
+	if concat, ok := n.(ast.Concat); ok {
+		for _, item := range concat.Items {
+			if _, ok := item.(ast.Empty); ok {
+				return ast.Empty{}, true
+			}
+		}
+	}
+	return n, false
+}
+
+// without drops the items the predicate matches, reporting whether there were any.
+func without(items []ast.Node, drop func(ast.Node) bool) ([]ast.Node, bool) {
+	var kept []ast.Node
+	for _, item := range items {
+		if !drop(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept, len(kept) != len(items)
+}
+
+func isEmpty(n ast.Node) bool {
+	_, ok := n.(ast.Empty)
+	return ok
+}
+
+func isEpsilon(n ast.Node) bool {
+	_, ok := n.(ast.Epsilon)
+	return ok
+}
+
+func emptyUnion(n ast.Node) (ast.Node, bool) {
+	if union, ok := n.(ast.Union); ok {
+		if items, changed := without(union.Items, isEmpty); changed {
+			return ast.Union{Items: items}, true
+		}
+	}
+	return n, false
+}
+
+func epsilonConcat(n ast.Node) (ast.Node, bool) {
+	if concat, ok := n.(ast.Concat); ok {
+		if items, changed := without(concat.Items, isEpsilon); changed {
+			return ast.Concat{Items: items}, true
+		}
+	}
+	return n, false
+}
+
+func idempotentUnion(n ast.Node) (ast.Node, bool) {
+	union, ok := n.(ast.Union)
+	if !ok {
+		return n, false
+	}
+	seen := make(map[string]bool)
+	var items []ast.Node
+	for _, item := range union.Items {
+		if k := key(item); !seen[k] {
+			seen[k] = true
+			items = append(items, item)
+		}
+	}
+	return ast.Union{Items: items}, len(items) != len(union.Items)
+}
+
+// plusToStar turns r.r* or r*.r into r* inside a union that also offers e.
+func plusToStar(n ast.Node) (ast.Node, bool) {
+	union, ok := n.(ast.Union)
+	if !ok {
+		return n, false
+	}
+	if _, hasEpsilon := without(union.Items, isEpsilon); !hasEpsilon {
+		return n, false
+	}
+	for i, item := range union.Items {
+		concat, ok := item.(ast.Concat)
+		if !ok || len(concat.Items) < 2 {
+			continue
+		}
+		last := len(concat.Items) - 1
+		var loop ast.Node
+		if star, ok := concat.Items[last].(ast.Star); ok && key(star.Sub) == key(join(concat.Items[:last])) {
+			loop = star
+		} else if star, ok := concat.Items[0].(ast.Star); ok && key(star.Sub) == key(join(concat.Items[1:])) {
+			loop = star
+		}
+		if loop != nil {
+			items := append([]ast.Node{}, union.Items...)
+			items[i] = loop
+			return ast.Union{Items: items}, true
+		}
+	}
+	return n, false
+}
+
+func join(items []ast.Node) ast.Node {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return ast.Concat{Items: items}
+}
+
+func nullableUnion(n ast.Node) (ast.Node, bool) {
+	union, ok := n.(ast.Union)
+	if !ok {
+		return n, false
+	}
+	items, hasEpsilon := without(union.Items, isEpsilon)
+	if !hasEpsilon {
+		return n, false
+	}
+	for _, item := range items {
+		if Nullable(item) {
+			return ast.Union{Items: items}, true
+		}
+	}
+	return n, false
+}
+
+func sortUnion(n ast.Node) (ast.Node, bool) {
+	union, ok := n.(ast.Union)
+	if !ok || sort.SliceIsSorted(union.Items, func(i, j int) bool { return key(union.Items[i]) < key(union.Items[j]) }) {
+		return n, false
+	}
+	items := append([]ast.Node{}, union.Items...)
+	sort.SliceStable(items, func(i, j int) bool { return key(items[i]) < key(items[j]) })
+	return ast.Union{Items: items}, true
+}
+
+func adjacentStars(n ast.Node) (ast.Node, bool) {
+	concat, ok := n.(ast.Concat)
+	if !ok {
+		return n, false
+	}
+	for i := 0; i+1 < len(concat.Items); i++ {
+		first, ok := concat.Items[i].(ast.Star)
+		second, ok2 := concat.Items[i+1].(ast.Star)
+		if ok && ok2 && key(first) == key(second) {
+			items := append(append([]ast.Node{}, concat.Items[:i+1]...), concat.Items[i+2:]...)
+			return ast.Concat{Items: items}, true
+		}
+	}
+	return n, false
+}
+
+func starStar(n ast.Node) (ast.Node, bool) {
+	if star, ok := n.(ast.Star); ok {
+		if inner, ok := star.Sub.(ast.Star); ok {
+			return inner, true
+		}
+	}
+	return n, false
+}
+
+func trivialStar(n ast.Node) (ast.Node, bool) {
+	if star, ok := n.(ast.Star); ok {
+		switch star.Sub.(type) {
+		case ast.Epsilon, ast.Empty:
+			return ast.Epsilon{}, true
+		}
+	}
+	return n, false
+}
+
+func starEpsilonUnion(n ast.Node) (ast.Node, bool) {
+	if star, ok := n.(ast.Star); ok {
+		if union, ok := star.Sub.(ast.Union); ok {
+			if items, changed := without(union.Items, isEpsilon); changed {
+				return ast.Star{Sub: ast.Union{Items: items}}, true
+			}
+		}
+	}
+	return n, false
+}
+
+// trivialRepeat replaces repetitions that the basic operators say more simply.
+func trivialRepeat(n ast.Node) (ast.Node, bool) {
+	repeat, ok := n.(ast.Repeat)
+	if !ok {
+		return n, false
+	}
+	switch repeat.Sub.(type) {
+	case ast.Epsilon:
+		return ast.Epsilon{}, true
+	case ast.Empty:
+		if repeat.Min == 0 {
+			return ast.Epsilon{}, true
+		}
+		return ast.Empty{}, true
+	}
+	switch {
+	case repeat.Max == 0:
+		return ast.Epsilon{}, true
+	case repeat.Min == 0 && repeat.Max < 0:
+		return ast.Star{Sub: repeat.Sub}, true
+	case repeat.Min == 1 && repeat.Max == 1:
+		return repeat.Sub, true
+	}
+	return n, false
+}
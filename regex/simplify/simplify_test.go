@@ -0,0 +1,46 @@
+package simplify
+
+import (
+	"github.com/jatin297/retoenfa/regex/ast"
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	a, b := ast.Literal{Symbol: "a"}, ast.Literal{Symbol: "b"}
+	cases := []struct {
+		input    ast.Node
+		expected string
+	}{
+		{ast.Concat{Items: []ast.Node{ast.Epsilon{}, a, ast.Epsilon{}}}, "a"},
+		{ast.Union{Items: []ast.Node{b, a, b}}, "a+b"},
+		{ast.Star{Sub: ast.Star{Sub: a}}, "a*"},
+		{ast.Concat{Items: []ast.Node{a, ast.Empty{}}}, "[]"},
+		{ast.Union{Items: []ast.Node{ast.Empty{}, a}}, "a"},
+		{ast.Union{Items: []ast.Node{ast.Union{Items: []ast.Node{b, ast.Concat{Items: []ast.Node{a, b}}}}, a}}, "a+a.b+b"},
+		{ast.Union{Items: []ast.Node{ast.Epsilon{}, ast.Concat{Items: []ast.Node{a, ast.Star{Sub: a}}}}}, "a*"},
+		{ast.Star{Sub: ast.Union{Items: []ast.Node{ast.Epsilon{}, a}}}, "a*"},
+		{ast.Concat{Items: []ast.Node{ast.Star{Sub: a}, ast.Star{Sub: a}, b}}, "a*.b"},
+		{ast.Repeat{Sub: a, Min: 1, Max: 1}, "a"},
+		{ast.Star{Sub: ast.Empty{}}, "e"},
+	}
+	for _, c := range cases {
+		simplified, steps := Simplify(c.input)
+		if printed := simplified.String(); printed != c.expected {
+			t.Errorf("%s: expect %s, but get %s", c.input, c.expected, printed)
+		}
+		if len(steps) == 0 {
+			t.Errorf("%s: expect a trace of the rules applied", c.input)
+		}
+	}
+
+	// An expression already in normal form is left alone
+	normal := ast.Concat{Items: []ast.Node{ast.Star{Sub: ast.Union{Items: []ast.Node{a, b}}}, a}}
+	if simplified, steps := Simplify(normal); simplified.String() != normal.String() || len(steps) != 0 {
+		t.Errorf("Expect %s unchanged, but get %s after %d steps", normal, simplified, len(steps))
+	}
+
+	_, steps := Simplify(ast.Concat{Items: []ast.Node{ast.Epsilon{}, ast.Star{Sub: ast.Star{Sub: a}}}})
+	if len(steps) != 3 || steps[0].Rule != "(r*)* = r*" || steps[1].Rule != "e.r = r" || steps[2].Rule != "(r) = r" {
+		t.Errorf("Expect the star, epsilon and unwrap rules in order, but get %v", steps)
+	}
+}
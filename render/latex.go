@@ -0,0 +1,66 @@
+// Package render formats transition tables for documents and text clients.
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/jatin297/retoenfa/diagram"
+	"github.com/jatin297/retoenfa/dto"
+	"io"
+	"strings"
+)
+
+// WriteLaTeX writes the transition table as a LaTeX tabular. The initial state is marked
+// with →, final states with *, and each cell lists its destinations as a set, ∅ when empty or
+// when it is the dead state. DFA tables get an extra column with the NFA states of each row.
+func WriteLaTeX(w io.Writer, table dto.Table) error {
+	withNFAStates := hasNFAStates(table)
+
+	columns := 1 + len(table.Symbols)
+	header := []string{"state"}
+	if withNFAStates {
+		columns++
+		header = append(header, "NFA states")
+	}
+	for _, symbol := range table.Symbols {
+		header = append(header, diagram.LaTeXEscape(symbol))
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "\\begin{tabular}{c|%s}\n", strings.Repeat("c", columns-1))
+	fmt.Fprintf(out, "\t%s \\\\\n", strings.Join(header, " & "))
+	fmt.Fprintln(out, "\t\\hline")
+	for _, row := range table.Rows {
+		cells := []string{latexState(table, row)}
+		if withNFAStates {
+			cells = append(cells, latexSet(row.NFAStates))
+		}
+		for _, next := range row.Next {
+			cells = append(cells, latexSet(next))
+		}
+		fmt.Fprintf(out, "\t%s \\\\\n", strings.Join(cells, " & "))
+	}
+	fmt.Fprintln(out, `\end{tabular}`)
+	return out.Flush()
+}
+
+func latexState(table dto.Table, row dto.TableRow) string {
+	label := stateName(row.State)
+	if row.State == dto.DeadState {
+		label = `\emptyset`
+	}
+	if row.Final {
+		label = "*" + label
+	}
+	if row.State == table.InitialState {
+		label = `\rightarrow ` + label
+	}
+	return "$" + label + "$"
+}
+
+func latexSet(states []int) string {
+	if isDead(states) {
+		return `$\emptyset$`
+	}
+	return `\{` + joinStates(states) + `\}`
+}
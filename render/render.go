@@ -0,0 +1,146 @@
+package render
+
+import (
+	"encoding/json"
+	"github.com/jatin297/retoenfa/dto"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Automaton is what the renderers draw from; enfa.ENFA and dfa.DFA both implement it.
+type Automaton interface {
+	GenerateFormattedTransitionTable() dto.Table
+	ExportDOT(w io.Writer) error
+	ExportSVG(w io.Writer) error
+	ExportJFLAP(w io.Writer) error
+	ExportMermaid(w io.Writer) error
+	ExportTikZ(w io.Writer) error
+}
+
+// Result is one conversion to be rendered: the automaton, and the body the JSON renderer
+// encodes, usually an API response wrapping the transition table.
+type Result struct {
+	Automaton Automaton
+	Body      any
+}
+
+// Renderer writes a Result in one format.
+type Renderer struct {
+	// Format is the name accepted by the format query parameter
+	Format string
+	// MediaType is matched against Accept headers and sent as the Content-Type
+	MediaType string
+	// Filename, when set, is suggested to clients through Content-Disposition
+	Filename string
+	Render   func(w io.Writer, result Result) error
+}
+
+// ContentType is the media type with a charset for textual formats.
+func (r Renderer) ContentType() string {
+	switch {
+	case strings.HasPrefix(r.MediaType, "text/"), r.MediaType == "application/json", r.MediaType == "application/x-jflap+xml":
+		return r.MediaType + "; charset=utf-8"
+	}
+	return r.MediaType
+}
+
+func table(write func(io.Writer, dto.Table) error) func(io.Writer, Result) error {
+	return func(w io.Writer, result Result) error {
+		return write(w, result.Automaton.GenerateFormattedTransitionTable())
+	}
+}
+
+// renderers lists every output format, the default first.
+var renderers = []Renderer{
+	{Format: "json", MediaType: "application/json", Render: func(w io.Writer, result Result) error {
+		return json.NewEncoder(w).Encode(result.Body)
+	}},
+	{Format: "csv", MediaType: "text/csv", Render: table(WriteCSV)},
+	{Format: "markdown", MediaType: "text/markdown", Render: table(WriteMarkdown)},
+	{Format: "text", MediaType: "text/plain", Render: table(WriteText)},
+	{Format: "latex", MediaType: "application/x-latex", Render: table(WriteLaTeX)},
+	{Format: "dot", MediaType: "text/vnd.graphviz", Render: func(w io.Writer, result Result) error {
+		return result.Automaton.ExportDOT(w)
+	}},
+	{Format: "svg", MediaType: "image/svg+xml", Render: func(w io.Writer, result Result) error {
+		return result.Automaton.ExportSVG(w)
+	}},
+	{Format: "mermaid", MediaType: "text/vnd.mermaid", Render: func(w io.Writer, result Result) error {
+		return result.Automaton.ExportMermaid(w)
+	}},
+	{Format: "tikz", MediaType: "application/x-tikz", Render: func(w io.Writer, result Result) error {
+		return result.Automaton.ExportTikZ(w)
+	}},
+	{Format: "jff", MediaType: "application/x-jflap+xml", Filename: "automaton.jff", Render: func(w io.Writer, result Result) error {
+		return result.Automaton.ExportJFLAP(w)
+	}},
+}
+
+// Formats returns the names of the registered formats.
+func Formats() []string {
+	formats := make([]string, len(renderers))
+	for i, renderer := range renderers {
+		formats[i] = renderer.Format
+	}
+	return formats
+}
+
+// Lookup returns the renderer registered for a format name.
+func Lookup(format string) (Renderer, bool) {
+	for _, renderer := range renderers {
+		if renderer.Format == format {
+			return renderer, true
+		}
+	}
+	return Renderer{}, false
+}
+
+// Negotiate picks the renderer for an Accept header, honouring q-values and wildcards. An
+// empty header gets the default renderer; ok is false when nothing acceptable is registered.
+func Negotiate(accept string) (renderer Renderer, ok bool) {
+	if strings.TrimSpace(accept) == "" {
+		return renderers[0], true
+	}
+
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, found := params["q"]; found {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+
+	for _, accepted := range ranges {
+		for _, candidate := range renderers {
+			if matches(accepted.mediaType, candidate.MediaType) {
+				return candidate, true
+			}
+		}
+	}
+	return Renderer{}, false
+}
+
+func matches(accepted, mediaType string) bool {
+	if accepted == "*/*" || accepted == mediaType {
+		return true
+	}
+	prefix, found := strings.CutSuffix(accepted, "/*")
+	return found && strings.HasPrefix(mediaType, prefix+"/")
+}
@@ -0,0 +1,138 @@
+package render
+
+import (
+	"github.com/jatin297/retoenfa/dto"
+	"io"
+	"strings"
+	"testing"
+)
+
+func sample() dto.Table {
+	return dto.Table{
+		InitialState: 0,
+		Symbols:      []string{"a", "ε"},
+		Rows: []dto.TableRow{
+			{State: 0, Next: [][]int{{}, {1, 2}}},
+			{State: 1, Next: [][]int{{2}, {}}},
+			{State: 2, Final: true, Next: [][]int{{}, {}}},
+		},
+	}
+}
+
+func TestWriteLaTeX(t *testing.T) {
+	var out strings.Builder
+	if err := WriteLaTeX(&out, sample()); err != nil {
+		t.Fatal(err)
+	}
+	expected := `\begin{tabular}{c|cc}
+	state & a & $\varepsilon$ \\
+	\hline
+	$\rightarrow 0$ & $\emptyset$ & \{1,2\} \\
+	$1$ & \{2\} & $\emptyset$ \\
+	$*2$ & $\emptyset$ & $\emptyset$ \\
+\end{tabular}
+`
+	if out.String() != expected {
+		t.Errorf("Expect\n%s\nbut get\n%s", expected, out.String())
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var out strings.Builder
+	if err := WriteCSV(&out, sample()); err != nil {
+		t.Fatal(err)
+	}
+	expected := "state,initial,final,a,ε\n0,true,false,,\"1,2\"\n1,false,false,2,\n2,false,true,,\n"
+	if out.String() != expected {
+		t.Errorf("Expect\n%s\nbut get\n%s", expected, out.String())
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var out strings.Builder
+	if err := WriteMarkdown(&out, sample()); err != nil {
+		t.Fatal(err)
+	}
+	expected := `| state | a | ε |
+| --- | --- | --- |
+| →0 | ∅ | {1,2} |
+| 1 | {2} | ∅ |
+| *2 | ∅ | ∅ |
+`
+	if out.String() != expected {
+		t.Errorf("Expect\n%s\nbut get\n%s", expected, out.String())
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var out strings.Builder
+	if err := WriteText(&out, sample()); err != nil {
+		t.Fatal(err)
+	}
+	expected := `state  a    ε
+-----  ---  -----
+→0     ∅    {1,2}
+1      {2}  ∅
+*2     ∅    ∅
+`
+	if out.String() != expected {
+		t.Errorf("Expect\n%s\nbut get\n%s", expected, out.String())
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]string{
+		"":                                   "json",
+		"*/*":                                "json",
+		"text/csv":                           "csv",
+		"text/markdown, text/plain;q=0.5":    "markdown",
+		"text/plain;q=0.5, image/svg+xml":    "svg",
+		"application/pdf, text/vnd.graphviz": "dot",
+		"text/*":                             "csv",
+		// A browser's default header must not trigger a JFLAP download
+		"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8": "json",
+		"application/x-jflap+xml": "jff",
+	}
+	for accept, format := range cases {
+		renderer, ok := Negotiate(accept)
+		if !ok || renderer.Format != format {
+			t.Errorf("Expect %q to negotiate %s, but get %s (ok=%v)", accept, format, renderer.Format, ok)
+		}
+	}
+
+	if _, ok := Negotiate("application/pdf"); ok {
+		t.Errorf("Expect application/pdf to be unacceptable")
+	}
+	if _, ok := Lookup("markdown"); !ok {
+		t.Errorf("Expect markdown to be registered")
+	}
+}
+
+func TestDeadState(t *testing.T) {
+	table := dto.Table{
+		InitialState: 0,
+		Symbols:      []string{"a", "b"},
+		Rows: []dto.TableRow{
+			{State: dto.DeadState, NFAStates: []int{}, Next: [][]int{{dto.DeadState}, {dto.DeadState}}},
+			{State: 0, NFAStates: []int{0}, Next: [][]int{{1}, {dto.DeadState}}},
+			{State: 1, Final: true, NFAStates: []int{1}, Next: [][]int{{dto.DeadState}, {dto.DeadState}}},
+		},
+	}
+
+	expected := map[string]string{
+		"csv":      "state,initial,final,nfa_states,a,b\n∅,false,false,,∅,∅\n0,true,false,0,1,∅\n1,false,true,1,∅,∅\n",
+		"markdown": "| state | nfa states | a | b |\n| --- | --- | --- | --- |\n| ∅ | ∅ | ∅ | ∅ |\n| →0 | {0} | {1} | ∅ |\n| *1 | {1} | ∅ | ∅ |\n",
+		"text":     "state  nfa states  a    b\n-----  ----------  ---  -\n∅      ∅           ∅    ∅\n→0     {0}         {1}  ∅\n*1     {1}         ∅    ∅\n",
+		"latex":    "\\begin{tabular}{c|ccc}\n\tstate & NFA states & a & b \\\\\n\t\\hline\n\t$\\emptyset$ & $\\emptyset$ & $\\emptyset$ & $\\emptyset$ \\\\\n\t$\\rightarrow 0$ & \\{0\\} & \\{1\\} & $\\emptyset$ \\\\\n\t$*1$ & \\{1\\} & $\\emptyset$ & $\\emptyset$ \\\\\n\\end{tabular}\n",
+	}
+	writers := map[string]func(io.Writer, dto.Table) error{"csv": WriteCSV, "markdown": WriteMarkdown, "text": WriteText, "latex": WriteLaTeX}
+	for format, write := range writers {
+		var out strings.Builder
+		if err := write(&out, table); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != expected[format] {
+			t.Errorf("%s: expect\n%s\nbut get\n%s", format, expected[format], out.String())
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package render
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"github.com/jatin297/retoenfa/dto"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// WriteCSV writes the transition table as CSV with a header row. Destinations are
+// comma-separated inside their cell, empty cells mean no transition and ∅ is the dead state.
+func WriteCSV(w io.Writer, table dto.Table) error {
+	withNFAStates := hasNFAStates(table)
+	header := []string{"state", "initial", "final"}
+	if withNFAStates {
+		header = append(header, "nfa_states")
+	}
+	header = append(header, table.Symbols...)
+
+	out := csv.NewWriter(w)
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	for _, row := range table.Rows {
+		record := []string{stateName(row.State), strconv.FormatBool(row.State == table.InitialState), strconv.FormatBool(row.Final)}
+		if withNFAStates {
+			record = append(record, joinStates(row.NFAStates))
+		}
+		for _, next := range row.Next {
+			record = append(record, joinStates(next))
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteMarkdown writes the transition table as a GitHub-flavoured Markdown table, marking the
+// initial state with → and final states with *.
+func WriteMarkdown(w io.Writer, table dto.Table) error {
+	cells := humanCells(table)
+	out := bufio.NewWriter(w)
+	for i, row := range cells {
+		for j, cell := range row {
+			row[j] = strings.NewReplacer(`\`, `\\`, "|", `\|`).Replace(cell)
+		}
+		fmt.Fprintf(out, "| %s |\n", strings.Join(row, " | "))
+		if i == 0 {
+			fmt.Fprintf(out, "|%s\n", strings.Repeat(" --- |", len(row)))
+		}
+	}
+	return out.Flush()
+}
+
+// WriteText writes the transition table as plain text with aligned columns, using the same
+// notation as WriteMarkdown.
+func WriteText(w io.Writer, table dto.Table) error {
+	cells := humanCells(table)
+	widths := make([]int, len(cells[0]))
+	for _, row := range cells {
+		for j, cell := range row {
+			if width := utf8.RuneCountInString(cell); width > widths[j] {
+				widths[j] = width
+			}
+		}
+	}
+
+	out := bufio.NewWriter(w)
+	for i, row := range cells {
+		padded := make([]string, len(row))
+		for j, cell := range row {
+			padded[j] = cell + strings.Repeat(" ", widths[j]-utf8.RuneCountInString(cell))
+		}
+		fmt.Fprintln(out, strings.TrimRight(strings.Join(padded, "  "), " "))
+		if i == 0 {
+			rules := make([]string, len(widths))
+			for j, width := range widths {
+				rules[j] = strings.Repeat("-", width)
+			}
+			fmt.Fprintln(out, strings.Join(rules, "  "))
+		}
+	}
+	return out.Flush()
+}
+
+// humanCells lays the table out as rows of text, header first, for the Markdown and text
+// renderers.
+func humanCells(table dto.Table) [][]string {
+	withNFAStates := hasNFAStates(table)
+	header := []string{"state"}
+	if withNFAStates {
+		header = append(header, "nfa states")
+	}
+	cells := [][]string{append(header, table.Symbols...)}
+
+	for _, row := range table.Rows {
+		label := stateName(row.State)
+		if row.Final {
+			label = "*" + label
+		}
+		if row.State == table.InitialState {
+			label = "→" + label
+		}
+		line := []string{label}
+		if withNFAStates {
+			line = append(line, setOf(row.NFAStates))
+		}
+		for _, next := range row.Next {
+			line = append(line, setOf(next))
+		}
+		cells = append(cells, line)
+	}
+	return cells
+}
+
+func hasNFAStates(table dto.Table) bool {
+	for _, row := range table.Rows {
+		if len(row.NFAStates) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// stateName prints a state number, or ∅ for the dead state.
+func stateName(state int) string {
+	if state == dto.DeadState {
+		return "∅"
+	}
+	return strconv.Itoa(state)
+}
+
+func joinStates(states []int) string {
+	members := make([]string, len(states))
+	for i, state := range states {
+		members[i] = stateName(state)
+	}
+	return strings.Join(members, ",")
+}
+
+// isDead reports whether a cell leads nowhere: no destination, or only the dead state.
+func isDead(states []int) bool {
+	return len(states) == 0 || len(states) == 1 && states[0] == dto.DeadState
+}
+
+func setOf(states []int) string {
+	if isDead(states) {
+		return "∅"
+	}
+	return "{" + joinStates(states) + "}"
+}
@@ -0,0 +1,61 @@
+package retoenfa
+
+import (
+	"fmt"
+	. "github.com/jatin297/retoenfa/dto"
+	"github.com/jatin297/retoenfa/regex/ast"
+)
+
+// build adds the Thompson fragment of the node and returns its start and final states.
+func (r *ReToeNFA) build(n ast.Node) (int, int) {
+	switch n := n.(type) {
+	case ast.Literal:
+		initialState := r.incCapacity()
+		finalState := r.incCapacity()
+		r.addEdge(initialState, n.Symbol, finalState)
+		return initialState, finalState
+	case ast.Epsilon:
+		initialState := r.incCapacity()
+		finalState := r.incCapacity()
+		r.addEdge(initialState, Epsilon, finalState)
+		return initialState, finalState
+	case ast.Empty:
+		// Two states with no way between them
+		initialState := r.incCapacity()
+		finalState := r.incCapacity()
+		return initialState, finalState
+	case ast.Class:
+		initialState := r.incCapacity()
+		finalState := r.incCapacity()
+		for _, symbol := range n.Matching(r.alphabet) {
+			r.addEdge(initialState, symbol, finalState)
+		}
+		return initialState, finalState
+	case ast.Concat:
+		if len(n.Items) == 0 {
+			return r.build(ast.Epsilon{})
+		}
+		start, end := r.build(n.Items[0])
+		for _, item := range n.Items[1:] {
+			itemStart, itemEnd := r.build(item)
+			start, end = r.doConcatenation(start, itemStart, end, itemEnd)
+		}
+		return start, end
+	case ast.Union:
+		if len(n.Items) == 0 {
+			return r.build(ast.Empty{})
+		}
+		start, end := r.build(n.Items[0])
+		for _, item := range n.Items[1:] {
+			itemStart, itemEnd := r.build(item)
+			start, end = r.doUnion(start, itemStart, end, itemEnd)
+		}
+		return start, end
+	case ast.Star:
+		subStart, subEnd := r.build(n.Sub)
+		return r.closure(subStart, subEnd)
+	case ast.Repeat:
+		return r.build(n.Expand())
+	}
+	panic(fmt.Sprintf("retoenfa: unknown node type %T", n))
+}
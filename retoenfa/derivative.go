@@ -0,0 +1,279 @@
+package retoenfa
+
+import (
+	"fmt"
+	. "github.com/jatin297/retoenfa/dto"
+	"github.com/jatin297/retoenfa/regex/ast"
+	"github.com/jatin297/retoenfa/regex/simplify"
+	"sort"
+)
+
+// maxDerivativeStates bounds the automata built from derivatives, since the Brzozowski DFA
+// can be exponentially larger than the expression.
+const maxDerivativeStates = 4096
+
+// derivatives builds automata whose states are regular expressions. Terms are kept in a normal
+// form (unions flattened, deduplicated and sorted, ∅ and ε absorbed) so that similar
+// expressions share a state and the construction terminates.
+type derivatives struct {
+	alphabet []Symbol
+}
+
+// buildDerivatives explores the terms reachable from the expression, one state per term.
+// successors returns the terms a term moves to on a symbol.
+func (r *ReToeNFA) buildDerivatives(root ast.Node, successors func(ast.Node, Symbol) []ast.Node) error {
+	states := make(map[string]int)
+	var queue []ast.Node
+	visit := func(term ast.Node) int {
+		key := termKey(term)
+		if state, ok := states[key]; ok {
+			return state
+		}
+		state := r.incCapacity()
+		states[key] = state
+		queue = append(queue, term)
+		if simplify.Nullable(term) {
+			r.enfa.MarkFinalState(state)
+		}
+		return state
+	}
+
+	initialState := visit(normalize(root))
+	r.enfa.SetInitialState(initialState)
+	for len(queue) > 0 {
+		if len(states) > maxDerivativeStates {
+			return &StateLimitError{Construction: "derivative construction", Limit: maxDerivativeStates}
+		}
+		term := queue[0]
+		queue = queue[1:]
+		src := states[termKey(term)]
+		for _, symbol := range r.alphabet {
+			for _, next := range successors(term, symbol) {
+				r.addEdge(src, symbol, visit(next))
+			}
+		}
+	}
+	return nil
+}
+
+// brzozowski returns the derivative of the term, omitted when it is ∅ so the DFA has no dead state.
+func (d derivatives) brzozowski(term ast.Node, symbol Symbol) []ast.Node {
+	next := d.derive(term, symbol)
+	if _, ok := next.(ast.Empty); ok {
+		return nil
+	}
+	return []ast.Node{next}
+}
+
+func (d derivatives) derive(term ast.Node, symbol Symbol) ast.Node {
+	switch term := term.(type) {
+	case ast.Literal, ast.Class:
+		if d.matches(term, symbol) {
+			return ast.Epsilon{}
+		}
+		return ast.Empty{}
+	case ast.Epsilon, ast.Empty:
+		return ast.Empty{}
+	case ast.Concat:
+		var terms []ast.Node
+		for i, item := range term.Items {
+			terms = append(terms, concat(append([]ast.Node{d.derive(item, symbol)}, term.Items[i+1:]...)...))
+			if !simplify.Nullable(item) {
+				break
+			}
+		}
+		return union(terms...)
+	case ast.Union:
+		terms := make([]ast.Node, len(term.Items))
+		for i, item := range term.Items {
+			terms[i] = d.derive(item, symbol)
+		}
+		return union(terms...)
+	case ast.Star:
+		return concat(d.derive(term.Sub, symbol), term)
+	case ast.Repeat:
+		return concat(d.derive(term.Sub, symbol), remaining(term))
+	}
+	panic(fmt.Sprintf("retoenfa: unknown node type %T", term))
+}
+
+// antimirov returns the partial derivatives of the term, a set whose union is its derivative.
+func (d derivatives) antimirov(term ast.Node, symbol Symbol) []ast.Node {
+	var terms []ast.Node
+	switch term := term.(type) {
+	case ast.Literal, ast.Class:
+		if d.matches(term, symbol) {
+			terms = append(terms, ast.Epsilon{})
+		}
+	case ast.Concat:
+		for i, item := range term.Items {
+			for _, partial := range d.antimirov(item, symbol) {
+				terms = append(terms, concat(append([]ast.Node{partial}, term.Items[i+1:]...)...))
+			}
+			if !simplify.Nullable(item) {
+				break
+			}
+		}
+	case ast.Union:
+		for _, item := range term.Items {
+			terms = append(terms, d.antimirov(item, symbol)...)
+		}
+	case ast.Star:
+		for _, partial := range d.antimirov(term.Sub, symbol) {
+			terms = append(terms, concat(partial, term))
+		}
+	case ast.Repeat:
+		for _, partial := range d.antimirov(term.Sub, symbol) {
+			terms = append(terms, concat(partial, remaining(term)))
+		}
+	}
+	return distinct(terms)
+}
+
+func (d derivatives) matches(term ast.Node, symbol Symbol) bool {
+	switch term := term.(type) {
+	case ast.Literal:
+		return term.Symbol == symbol
+	case ast.Class:
+		for _, member := range term.Matching(d.alphabet) {
+			if member == symbol {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// remaining is what is left of a repetition after one copy of its body has been read.
+func remaining(term ast.Repeat) ast.Node {
+	min, max := term.Min-1, term.Max-1
+	if min < 0 {
+		min = 0
+	}
+	if term.Max < 0 {
+		max = -1
+	}
+	return repeat(term.Sub, min, max)
+}
+
+// normalize rebuilds the tree bottom up with the simplifying constructors.
+func normalize(term ast.Node) ast.Node {
+	switch term := term.(type) {
+	case ast.Concat:
+		items := make([]ast.Node, len(term.Items))
+		for i, item := range term.Items {
+			items[i] = normalize(item)
+		}
+		return concat(items...)
+	case ast.Union:
+		items := make([]ast.Node, len(term.Items))
+		for i, item := range term.Items {
+			items[i] = normalize(item)
+		}
+		return union(items...)
+	case ast.Star:
+		return star(normalize(term.Sub))
+	case ast.Repeat:
+		return repeat(normalize(term.Sub), term.Min, term.Max)
+	case ast.Class:
+		if !term.Negated && len(term.Members) == 0 {
+			return ast.Empty{}
+		}
+	}
+	return term
+}
+
+func concat(items ...ast.Node) ast.Node {
+	var flat []ast.Node
+	for _, item := range items {
+		switch item := item.(type) {
+		case ast.Empty:
+			return ast.Empty{}
+		case ast.Epsilon:
+		case ast.Concat:
+			flat = append(flat, item.Items...)
+		default:
+			flat = append(flat, item)
+		}
+	}
+	switch len(flat) {
+	case 0:
+		return ast.Epsilon{}
+	case 1:
+		return flat[0]
+	}
+	return ast.Concat{Items: flat}
+}
+
+func union(items ...ast.Node) ast.Node {
+	var flat []ast.Node
+	for _, item := range items {
+		switch item := item.(type) {
+		case ast.Empty:
+		case ast.Union:
+			flat = append(flat, item.Items...)
+		default:
+			flat = append(flat, item)
+		}
+	}
+	flat = distinct(flat)
+	switch len(flat) {
+	case 0:
+		return ast.Empty{}
+	case 1:
+		return flat[0]
+	}
+	return ast.Union{Items: flat}
+}
+
+func star(sub ast.Node) ast.Node {
+	switch sub.(type) {
+	case ast.Empty, ast.Epsilon:
+		return ast.Epsilon{}
+	case ast.Star:
+		return sub
+	}
+	return ast.Star{Sub: sub}
+}
+
+func repeat(sub ast.Node, min, max int) ast.Node {
+	switch sub.(type) {
+	case ast.Epsilon:
+		return ast.Epsilon{}
+	case ast.Empty:
+		if min == 0 {
+			return ast.Epsilon{}
+		}
+		return ast.Empty{}
+	}
+	switch {
+	case max == 0:
+		return ast.Epsilon{}
+	case min == 0 && max < 0:
+		return star(sub)
+	case min == 1 && max == 1:
+		return sub
+	}
+	return ast.Repeat{Sub: sub, Min: min, Max: max}
+}
+
+// distinct removes duplicate and ∅ terms and sorts the rest by their printed form.
+func distinct(terms []ast.Node) []ast.Node {
+	seen := make(map[string]bool)
+	var result []ast.Node
+	for _, term := range terms {
+		if _, ok := term.(ast.Empty); ok {
+			continue
+		}
+		if key := termKey(term); !seen[key] {
+			seen[key] = true
+			result = append(result, term)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return termKey(result[i]) < termKey(result[j]) })
+	return result
+}
+
+func termKey(term ast.Node) string {
+	return ast.Print(term, ast.Conventional)
+}
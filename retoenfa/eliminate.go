@@ -0,0 +1,236 @@
+package retoenfa
+
+import (
+	"fmt"
+	. "github.com/jatin297/retoenfa/dto"
+	"github.com/jatin297/retoenfa/enfa"
+	"github.com/jatin297/retoenfa/regex/ast"
+	"github.com/jatin297/retoenfa/regex/simplify"
+	"sort"
+)
+
+// EliminationOrder picks which state ToRegex removes next. The order does not change the
+// language, only the size and shape of the resulting expression.
+type EliminationOrder int
+
+const (
+	// EliminateInOrder removes states by ascending number.
+	EliminateInOrder EliminationOrder = iota
+	// EliminateMinDegree removes the state with the fewest incoming times outgoing edges first.
+	EliminateMinDegree
+	// EliminateMinWeight removes the state whose elimination adds the least expression text first.
+	EliminateMinWeight
+)
+
+// ParseEliminationOrder resolves an elimination order name as accepted by the API. The empty
+// name is EliminateMinWeight.
+func ParseEliminationOrder(name string) (EliminationOrder, error) {
+	switch name {
+	case "in-order":
+		return EliminateInOrder, nil
+	case "min-degree":
+		return EliminateMinDegree, nil
+	case "", "min-weight":
+		return EliminateMinWeight, nil
+	}
+	return EliminateMinWeight, fmt.Errorf("unknown elimination order: %s", name)
+}
+
+// maxLabelSymbols bounds the symbols written across all edge labels during state elimination,
+// as each elimination can multiply the expression text.
+const maxLabelSymbols = 1 << 16
+
+// edge is the pair of states a gnfa label connects.
+type edge struct{ src, dst int }
+
+// gnfa is a generalized automaton whose edges are labelled with expressions, at most one per
+// pair of states. Its start and final states are fresh, below every ENFA state. sizes holds an
+// upper bound on the symbols in each label and total their sum.
+type gnfa struct {
+	start int
+	final int
+	edges map[edge]ast.Node
+	sizes map[edge]int
+	total int
+	out   map[int]map[int]bool
+	in    map[int]map[int]bool
+}
+
+// ToRegex converts the automaton into an equivalent regular expression by state elimination:
+// a fresh start and final state are added, then every original state is removed in turn and
+// the paths through it are rewritten as expressions on the remaining edges. The result is
+// passed through simplify.Simplify. A *StateLimitError is returned when the labels would exceed
+// maxLabelSymbols.
+func ToRegex(e *enfa.ENFA, order EliminationOrder) (ast.Node, error) {
+	lowest := DeadState
+	for _, state := range e.States() {
+		if state < lowest {
+			lowest = state
+		}
+	}
+	g := &gnfa{start: lowest - 1, final: lowest - 2, edges: make(map[edge]ast.Node), sizes: make(map[edge]int), out: make(map[int]map[int]bool), in: make(map[int]map[int]bool)}
+
+	symbols := append([]Symbol{Epsilon}, e.InputSymbols()...)
+	for _, state := range e.States() {
+		for _, symbol := range symbols {
+			for _, next := range e.NextStates(state, symbol) {
+				g.add(state, next, label(symbol), 1)
+			}
+		}
+		if e.IsFinalState(state) {
+			g.add(state, g.final, ast.Epsilon{}, 0)
+		}
+	}
+	g.add(g.start, e.InitialState(), ast.Epsilon{}, 0)
+
+	remaining := make(map[int]bool)
+	for _, state := range e.States() {
+		remaining[state] = true
+	}
+	for len(remaining) > 0 {
+		state := g.pick(remaining, order)
+		if err := g.eliminate(state); err != nil {
+			return nil, err
+		}
+		delete(remaining, state)
+	}
+
+	if result, ok := g.edges[edge{g.start, g.final}]; ok {
+		simplified, _ := simplify.Simplify(result)
+		return simplified, nil
+	}
+	return ast.Empty{}, nil
+}
+
+func label(symbol Symbol) ast.Node {
+	if symbol.IsEpsilon() {
+		return ast.Epsilon{}
+	}
+	return ast.Literal{Symbol: symbol}
+}
+
+// add unions the expression, holding at most size symbols, into the edge from src to dst.
+func (g *gnfa) add(src, dst int, expression ast.Node, size int) error {
+	if _, ok := expression.(ast.Empty); ok {
+		return nil
+	}
+	if g.total += size; g.total > maxLabelSymbols {
+		return &StateLimitError{Construction: "state elimination", Limit: maxLabelSymbols, Unit: "symbols"}
+	}
+	key := edge{src, dst}
+	if existing, ok := g.edges[key]; ok {
+		expression = union(existing, expression)
+	}
+	g.edges[key] = expression
+	g.sizes[key] += size
+
+	if g.out[src] == nil {
+		g.out[src] = make(map[int]bool)
+	}
+	if g.in[dst] == nil {
+		g.in[dst] = make(map[int]bool)
+	}
+	g.out[src][dst] = true
+	g.in[dst][src] = true
+	return nil
+}
+
+// remove deletes the edge from src to dst and its share of the label total.
+func (g *gnfa) remove(src, dst int) {
+	key := edge{src, dst}
+	g.total -= g.sizes[key]
+	delete(g.edges, key)
+	delete(g.sizes, key)
+}
+
+// eliminate removes the state, replacing every path p → state → r with an edge labelled
+// in.loop*.out.
+func (g *gnfa) eliminate(state int) error {
+	loop := ast.Node(ast.Epsilon{})
+	self := edge{state, state}
+	if expression, ok := g.edges[self]; ok {
+		loop = star(expression)
+	}
+
+	predecessors, successors := g.neighbours(state)
+	for _, p := range predecessors {
+		incoming := edge{p, state}
+		for _, r := range successors {
+			outgoing := edge{state, r}
+			size := g.sizes[incoming] + g.sizes[self] + g.sizes[outgoing]
+			if err := g.add(p, r, concat(g.edges[incoming], loop, g.edges[outgoing]), size); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, p := range predecessors {
+		g.remove(p, state)
+		delete(g.out[p], state)
+	}
+	for _, r := range successors {
+		g.remove(state, r)
+		delete(g.in[r], state)
+	}
+	g.remove(state, state)
+	delete(g.in, state)
+	delete(g.out, state)
+	return nil
+}
+
+// neighbours returns the sorted states with an edge into and out of the state, itself excluded.
+func (g *gnfa) neighbours(state int) ([]int, []int) {
+	collect := func(set map[int]bool) []int {
+		var states []int
+		for other := range set {
+			if other != state {
+				states = append(states, other)
+			}
+		}
+		sort.Ints(states)
+		return states
+	}
+	return collect(g.in[state]), collect(g.out[state])
+}
+
+// pick chooses the next state to eliminate, breaking ties by the lower state number.
+func (g *gnfa) pick(remaining map[int]bool, order EliminationOrder) int {
+	var states []int
+	for state := range remaining {
+		states = append(states, state)
+	}
+	sort.Ints(states)
+	if order == EliminateInOrder {
+		return states[0]
+	}
+
+	best, bestCost := states[0], g.cost(states[0], order)
+	for _, state := range states[1:] {
+		if cost := g.cost(state, order); cost < bestCost {
+			best, bestCost = state, cost
+		}
+	}
+	return best
+}
+
+func (g *gnfa) cost(state int, order EliminationOrder) int {
+	predecessors, successors := g.neighbours(state)
+	if order == EliminateMinDegree {
+		return len(predecessors) * len(successors)
+	}
+
+	// Every incoming label is copied once per outgoing edge and vice versa, and the loop
+	// once per pair; the labels already on the automaton are subtracted
+	loop := 0
+	if expression, ok := g.edges[edge{state, state}]; ok {
+		loop = len(termKey(expression))
+	}
+	weight := loop * (len(predecessors)*len(successors) - 1)
+	for _, p := range predecessors {
+		weight += len(termKey(g.edges[edge{p, state}])) * (len(successors) - 1)
+	}
+	for _, r := range successors {
+		weight += len(termKey(g.edges[edge{state, r}])) * (len(predecessors) - 1)
+	}
+	return weight
+}
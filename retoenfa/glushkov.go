@@ -0,0 +1,184 @@
+package retoenfa
+
+import (
+	"fmt"
+	. "github.com/jatin297/retoenfa/dto"
+	"github.com/jatin297/retoenfa/regex/ast"
+	"sort"
+)
+
+// Construction selects how the automaton is built from the parse tree.
+type Construction int
+
+const (
+	// ConstructionThompson glues one small fragment per operator together with epsilon edges.
+	ConstructionThompson Construction = iota
+	// ConstructionGlushkov builds the epsilon-free position automaton: one state per literal
+	// occurrence plus the initial state.
+	ConstructionGlushkov
+	// ConstructionBrzozowski builds a DFA whose states are the distinct derivatives of the
+	// expression. Words leading to ∅ have no transition rather than a dead state.
+	ConstructionBrzozowski
+	// ConstructionAntimirov builds an epsilon-free NFA whose states are partial derivatives.
+	ConstructionAntimirov
+)
+
+// ParseConstruction resolves a construction name as accepted by the API. The empty name is
+// ConstructionThompson.
+func ParseConstruction(name string) (Construction, error) {
+	switch name {
+	case "", "thompson":
+		return ConstructionThompson, nil
+	case "glushkov":
+		return ConstructionGlushkov, nil
+	case "brzozowski":
+		return ConstructionBrzozowski, nil
+	case "antimirov":
+		return ConstructionAntimirov, nil
+	}
+	return ConstructionThompson, fmt.Errorf("unknown construction: %s", name)
+}
+
+// SetConstruction selects the construction StartParse uses, ConstructionThompson by default.
+func (r *ReToeNFA) SetConstruction(construction Construction) {
+	r.construction = construction
+}
+
+// positions summarizes a subexpression for the Glushkov construction: whether it matches the
+// empty string, and the positions that can start and end its words.
+type positions struct {
+	nullable bool
+	first    []int
+	last     []int
+}
+
+// maxGlushkovTransitions bounds the transitions of the position automaton, which can need one
+// per pair of positions, as in (a|a|…|a)*.
+const maxGlushkovTransitions = 1 << 16
+
+// glushkov numbers the literal occurrences of the tree and wires up the position automaton.
+// State 0 is the initial state and position p becomes state p. transitions counts the edges
+// the follow pairs will need.
+type glushkov struct {
+	alphabet    []Symbol
+	labels      [][]Symbol
+	follow      map[int]StateSet
+	transitions int
+}
+
+func (r *ReToeNFA) buildGlushkov(root ast.Node) error {
+	g := &glushkov{alphabet: r.alphabet, labels: [][]Symbol{nil}, follow: make(map[int]StateSet)}
+	summary := g.visit(root)
+	for _, position := range summary.first {
+		g.transitions += max(len(g.labels[position]), 1)
+	}
+	if g.transitions > maxGlushkovTransitions {
+		return &StateLimitError{Construction: "glushkov construction", Limit: maxGlushkovTransitions, Unit: "transitions"}
+	}
+
+	initialState := r.incCapacity()
+	for position := 1; position < len(g.labels); position++ {
+		r.incCapacity()
+	}
+
+	connect := func(src int, destinations []int) {
+		for _, dst := range destinations {
+			for _, symbol := range g.labels[dst] {
+				r.addEdge(src, symbol, dst)
+			}
+		}
+	}
+	connect(initialState, summary.first)
+	for position := 1; position < len(g.labels); position++ {
+		connect(position, sortedStates(g.follow[position]))
+	}
+
+	r.enfa.SetInitialState(initialState)
+	if summary.nullable {
+		r.enfa.MarkFinalState(initialState)
+	}
+	for _, position := range summary.last {
+		r.enfa.MarkFinalState(position)
+	}
+	return nil
+}
+
+func (g *glushkov) position(labels []Symbol) positions {
+	g.labels = append(g.labels, labels)
+	position := len(g.labels) - 1
+	return positions{first: []int{position}, last: []int{position}}
+}
+
+func (g *glushkov) visit(n ast.Node) positions {
+	switch n := n.(type) {
+	case ast.Literal:
+		return g.position([]Symbol{n.Symbol})
+	case ast.Class:
+		return g.position(n.Matching(g.alphabet))
+	case ast.Epsilon:
+		return positions{nullable: true}
+	case ast.Empty:
+		return positions{}
+	case ast.Concat:
+		result := positions{nullable: true}
+		for _, item := range n.Items {
+			next := g.visit(item)
+			g.link(result.last, next.first)
+			if result.nullable {
+				result.first = append(result.first, next.first...)
+			}
+			if next.nullable {
+				result.last = append(result.last, next.last...)
+			} else {
+				result.last = next.last
+			}
+			result.nullable = result.nullable && next.nullable
+		}
+		return result
+	case ast.Union:
+		var result positions
+		for _, item := range n.Items {
+			next := g.visit(item)
+			result.nullable = result.nullable || next.nullable
+			result.first = append(result.first, next.first...)
+			result.last = append(result.last, next.last...)
+		}
+		return result
+	case ast.Star:
+		result := g.visit(n.Sub)
+		g.link(result.last, result.first)
+		result.nullable = true
+		return result
+	case ast.Repeat:
+		return g.visit(n.Expand())
+	}
+	panic(fmt.Sprintf("retoenfa: unknown node type %T", n))
+}
+
+// link records that every position in from may be followed by every position in to. It stops
+// once the pairs need more than maxGlushkovTransitions edges.
+func (g *glushkov) link(from, to []int) {
+	for _, src := range from {
+		if g.follow[src] == nil {
+			g.follow[src] = make(StateSet)
+		}
+		for _, dst := range to {
+			if g.transitions > maxGlushkovTransitions {
+				return
+			}
+			if !g.follow[src][dst] {
+				g.follow[src][dst] = true
+				g.transitions += max(len(g.labels[dst]), 1)
+			}
+		}
+	}
+}
+
+func sortedStates(set StateSet) []int {
+	states := make([]int, 0, len(set))
+	for state := range set {
+		states = append(states, state)
+	}
+	sort.Ints(states)
+	return states
+}
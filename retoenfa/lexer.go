@@ -0,0 +1,228 @@
+package retoenfa
+
+import (
+	"fmt"
+	. "github.com/jatin297/retoenfa/dto"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxRepeat bounds the counts of {m,n} repetitions, since each copy is built out in full.
+const maxRepeat = 1000
+
+// maxExpandedSize bounds the number of symbols an expression has once every repetition is
+// written out, so nested counts such as (a{1000}){1000} cannot multiply past it.
+const maxExpandedSize = 10000
+
+// maxClassSize bounds the number of symbols a character class may list, ranges included.
+const maxClassSize = 1024
+
+type tokenKind int
+
+const (
+	tokenLiteral tokenKind = iota
+	tokenEpsilon
+	tokenUnion
+	tokenConcat
+	tokenStar
+	tokenPlus
+	tokenOptional
+	tokenRepeat
+	tokenClass
+	tokenOpenParen
+	tokenCloseParen
+	tokenEnd
+)
+
+type token struct {
+	kind   tokenKind
+	symbol Symbol
+	text   string
+	offset int
+	// min and max bound a tokenRepeat, max is -1 when unbounded
+	min int
+	max int
+	// members of a tokenClass, taken from the complement of the alphabet when negated
+	members []Symbol
+	negated bool
+}
+
+// describe renders the token the way it appears in error messages.
+func (t token) describe() string {
+	if t.kind == tokenEnd {
+		return "end of input"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// tokenize splits an expression into tokens of the given dialect. A backslash makes the
+// character after it a literal, so operators such as \e, \+, \., \*, \( and \) stand for themselves.
+// Both dialects accept character classes like [abc], [a-z] and [^0-9]; ModeConventional also
+// reads . as a wildcard, which ModeClassic spells [^].
+func tokenize(expression string, mode Mode) ([]token, error) {
+	var tokens []token
+	for offset := 0; offset < len(expression); {
+		char, size := utf8.DecodeRuneInString(expression[offset:])
+		if char == utf8.RuneError && size == 1 {
+			return nil, &ParseError{Offset: offset, Expected: "valid UTF-8", Found: fmt.Sprintf("byte %#x", expression[offset])}
+		}
+
+		current := token{text: expression[offset : offset+size], offset: offset}
+		switch {
+		case char == '\\':
+			if offset+size == len(expression) {
+				return nil, &ParseError{Offset: offset + size, Expected: "character after escape", Found: "end of input"}
+			}
+			escaped, escapedSize := utf8.DecodeRuneInString(expression[offset+size:])
+			if escaped == utf8.RuneError && escapedSize == 1 {
+				return nil, &ParseError{Offset: offset + size, Expected: "valid UTF-8", Found: fmt.Sprintf("byte %#x", expression[offset+size])}
+			}
+			size += escapedSize
+			current.kind = tokenLiteral
+			current.symbol = Literal(escaped)
+			current.text = expression[offset : offset+size]
+		case char == '*':
+			current.kind = tokenStar
+		case char == '[':
+			class, err := scanClass(expression, offset)
+			if err != nil {
+				return nil, err
+			}
+			current = class
+			size = len(class.text)
+		case char == '(':
+			current.kind = tokenOpenParen
+		case char == ')':
+			current.kind = tokenCloseParen
+		case mode == ModeClassic && char == 'e':
+			current.kind = tokenEpsilon
+		case mode == ModeClassic && char == '+':
+			current.kind = tokenUnion
+		case mode == ModeClassic && char == '.':
+			current.kind = tokenConcat
+		case mode == ModeConventional && char == '|':
+			current.kind = tokenUnion
+		case mode == ModeConventional && char == '+':
+			current.kind = tokenPlus
+		case mode == ModeConventional && char == '?':
+			current.kind = tokenOptional
+		case mode == ModeConventional && char == '.':
+			current.kind = tokenClass
+			current.negated = true
+		case mode == ModeConventional && char == '{':
+			repeat, err := scanRepeat(expression, offset)
+			if err != nil {
+				return nil, err
+			}
+			current = repeat
+			size = len(repeat.text)
+		default:
+			current.kind = tokenLiteral
+			current.symbol = Literal(char)
+		}
+
+		tokens = append(tokens, current)
+		offset += size
+	}
+	return append(tokens, token{kind: tokenEnd, offset: len(expression)}), nil
+}
+
+// scanRepeat reads a counted repetition {m}, {m,} or {m,n} starting at the opening brace.
+func scanRepeat(expression string, offset int) (token, error) {
+	closing := strings.IndexByte(expression[offset:], '}')
+	if closing < 0 {
+		return token{}, &ParseError{Offset: len(expression), Expected: `"}"`, Found: "end of input"}
+	}
+	text := expression[offset : offset+closing+1]
+	bounds := text[1 : len(text)-1]
+
+	invalid := &ParseError{Offset: offset, Expected: "repetition {m}, {m,} or {m,n}", Found: fmt.Sprintf("%q", text)}
+	minText, maxText, hasComma := strings.Cut(bounds, ",")
+
+	min, err := strconv.Atoi(minText)
+	if err != nil || min < 0 || min > maxRepeat {
+		return token{}, invalid
+	}
+
+	max := min
+	if hasComma {
+		max = -1
+		if maxText != "" {
+			max, err = strconv.Atoi(maxText)
+			if err != nil || max < min || max > maxRepeat {
+				return token{}, invalid
+			}
+		}
+	}
+	return token{kind: tokenRepeat, text: text, offset: offset, min: min, max: max}, nil
+}
+
+// scanClass reads a bracketed character class such as [abc], [a-z] or [^0-9] starting at the
+// opening bracket. Inside the brackets a backslash escapes the next character, and - is a
+// literal at either end of the class.
+func scanClass(expression string, offset int) (token, error) {
+	position := offset + 1
+	class := token{kind: tokenClass, offset: offset}
+	if position < len(expression) && expression[position] == '^' {
+		class.negated = true
+		position++
+	}
+
+	readChar := func() (rune, error) {
+		if expression[position] == '\\' {
+			position++
+			if position == len(expression) {
+				return 0, &ParseError{Offset: position, Expected: "character after escape", Found: "end of input"}
+			}
+		}
+		char, size := utf8.DecodeRuneInString(expression[position:])
+		if char == utf8.RuneError && size == 1 {
+			return 0, &ParseError{Offset: position, Expected: "valid UTF-8", Found: fmt.Sprintf("byte %#x", expression[position])}
+		}
+		position += size
+		return char, nil
+	}
+
+	seen := make(map[Symbol]bool)
+	add := func(char rune) {
+		if symbol := Literal(char); !seen[symbol] {
+			seen[symbol] = true
+			class.members = append(class.members, symbol)
+		}
+	}
+
+	for {
+		if position == len(expression) {
+			return token{}, &ParseError{Offset: position, Expected: `"]"`, Found: "end of input"}
+		}
+		if expression[position] == ']' {
+			position++
+			break
+		}
+
+		rangeStart := position
+		low, err := readChar()
+		if err != nil {
+			return token{}, err
+		}
+		if position+1 < len(expression) && expression[position] == '-' && expression[position+1] != ']' {
+			position++
+			high, err := readChar()
+			if err != nil {
+				return token{}, err
+			}
+			if high < low || int(high-low)+1+len(class.members) > maxClassSize {
+				return token{}, &ParseError{Offset: rangeStart, Expected: fmt.Sprintf("ascending range of at most %d characters", maxClassSize), Found: fmt.Sprintf("%q", expression[rangeStart:position])}
+			}
+			for char := low; char <= high; char++ {
+				add(char)
+			}
+			continue
+		}
+		add(low)
+	}
+
+	class.text = expression[offset:position]
+	return class, nil
+}
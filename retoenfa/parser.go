@@ -0,0 +1,300 @@
+package retoenfa
+
+import (
+	"fmt"
+	"github.com/jatin297/retoenfa/regex/ast"
+)
+
+// ParseError reports the byte offset where a regular expression stopped matching the grammar,
+// together with the token the parser expected there.
+type ParseError struct {
+	Offset   int
+	Expected string
+	Found    string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("offset %d: expected %s, found %s", e.Offset, e.Expected, e.Found)
+}
+
+// Mode selects the dialect regular expressions are written in.
+type Mode = ast.Dialect
+
+const (
+	// ModeClassic is the textbook dialect: + is union, . is explicit concatenation, * is the
+	// Kleene star and e stands for epsilon.
+	ModeClassic = ast.Classic
+	// ModeConventional follows everyday regex syntax: juxtaposition concatenates, | is union,
+	// and *, +, ?, {m}, {m,} and {m,n} are postfix repetitions. An empty alternative is epsilon.
+	ModeConventional = ast.Conventional
+)
+
+// ParseMode resolves a dialect name as accepted by the API. The empty name is ModeClassic.
+func ParseMode(name string) (Mode, error) {
+	return ast.ParseDialect(name)
+}
+
+// Parse builds the parse tree of an expression. In ModeClassic the grammar is
+//
+//	union   := concat ('+' concat)*
+//	concat  := postfix ('.' postfix)*
+//	postfix := atom '*'*
+//	atom    := literal | class | 'e' | '(' union ')'
+//
+// and in ModeConventional it is
+//
+//	union   := concat ('|' concat)*
+//	concat  := postfix*
+//	postfix := atom ('*' | '+' | '?' | '{m}' | '{m,}' | '{m,n}')*
+//	atom    := literal | class | '.' | '(' union ')'
+//
+// Chains of the same operator become one n-ary node, and the empty class [] is ast.Empty.
+func Parse(expression string, mode Mode) (*ast.AST, error) {
+	tokens, err := tokenize(expression, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, mode: mode}
+	root, err := p.parseUnion()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenEnd, "operator or end of input"); err != nil {
+		return nil, err
+	}
+	return &ast.AST{Root: root, Dialect: mode}, nil
+}
+
+type parser struct {
+	tokens   []token
+	position int
+	mode     Mode
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.position]
+}
+
+func (p *parser) next() token {
+	current := p.tokens[p.position]
+	if current.kind != tokenEnd {
+		p.position++
+	}
+	return current
+}
+
+func (p *parser) expect(kind tokenKind, expected string) (token, error) {
+	current := p.peek()
+	if current.kind != kind {
+		return current, &ParseError{Offset: current.offset, Expected: expected, Found: current.describe()}
+	}
+	return p.next(), nil
+}
+
+func (p *parser) parseUnion() (ast.Node, error) {
+	first, err := p.parseConcat()
+	if err != nil {
+		return nil, err
+	}
+	items := []ast.Node{first}
+	size := expandedSize(first, 0)
+	for p.peek().kind == tokenUnion {
+		p.next()
+		start := p.peek()
+		item, err := p.parseConcat()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if size, err = p.grow(size, item, start); err != nil {
+			return nil, err
+		}
+	}
+	if len(items) == 1 {
+		return first, nil
+	}
+	return ast.Union{Items: items}, nil
+}
+
+func (p *parser) parseConcat() (ast.Node, error) {
+	if p.mode == ModeConventional {
+		return p.parseJuxtaposition()
+	}
+
+	first, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	items := []ast.Node{first}
+	size := expandedSize(first, 0)
+	for p.peek().kind == tokenConcat {
+		p.next()
+		start := p.peek()
+		item, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if size, err = p.grow(size, item, start); err != nil {
+			return nil, err
+		}
+	}
+	if len(items) == 1 {
+		return first, nil
+	}
+	return ast.Concat{Items: items}, nil
+}
+
+// parseJuxtaposition concatenates adjacent atoms; an empty sequence stands for epsilon.
+func (p *parser) parseJuxtaposition() (ast.Node, error) {
+	var items []ast.Node
+	size := 0
+	for {
+		switch p.peek().kind {
+		case tokenLiteral, tokenClass, tokenOpenParen:
+			start := p.peek()
+			item, err := p.parsePostfix()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			if size, err = p.grow(size, item, start); err != nil {
+				return nil, err
+			}
+			continue
+		case tokenStar, tokenPlus, tokenOptional, tokenRepeat:
+			if len(items) == 0 {
+				current := p.peek()
+				return nil, &ParseError{Offset: current.offset, Expected: p.expectedAtom(), Found: current.describe()}
+			}
+		}
+
+		switch len(items) {
+		case 0:
+			return ast.Epsilon{}, nil
+		case 1:
+			return items[0], nil
+		}
+		return ast.Concat{Items: items}, nil
+	}
+}
+
+func (p *parser) parsePostfix() (ast.Node, error) {
+	sub, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		current := p.peek()
+		switch current.kind {
+		case tokenStar:
+			sub = ast.Star{Sub: sub}
+		case tokenPlus:
+			sub = ast.Repeat{Sub: sub, Min: 1, Max: -1}
+		case tokenOptional:
+			sub = ast.Repeat{Sub: sub, Min: 0, Max: 1}
+		case tokenRepeat:
+			sub = ast.Repeat{Sub: sub, Min: current.min, Max: current.max}
+			if _, err := p.grow(0, sub, current); err != nil {
+				return nil, err
+			}
+		default:
+			return sub, nil
+		}
+		p.next()
+	}
+}
+
+// grow adds the expanded size of a node starting at the given token to size, rejecting the
+// expression once the total exceeds maxExpandedSize. The alphabet is not known yet, so negated
+// classes count once here and StartParse checks them again.
+func (p *parser) grow(size int, n ast.Node, at token) (int, error) {
+	size += expandedSize(n, 0)
+	if size > maxExpandedSize {
+		return size, &ParseError{
+			Offset:   at.offset,
+			Expected: fmt.Sprintf("at most %d symbols with repetitions expanded", maxExpandedSize),
+			Found:    at.describe(),
+		}
+	}
+	return size, nil
+}
+
+// expandedSize counts the symbols of the node with every repetition written out as by
+// ast.Repeat.Expand, saturating just above maxExpandedSize. A class counts once per symbol it
+// matches, taken as the whole alphabet of the given size when negated.
+func expandedSize(n ast.Node, alphabet int) int {
+	saturate := func(size int) int {
+		if size > maxExpandedSize {
+			return maxExpandedSize + 1
+		}
+		return size
+	}
+
+	switch node := n.(type) {
+	case ast.Concat:
+		size := 0
+		for _, item := range node.Items {
+			size = saturate(size + expandedSize(item, alphabet))
+		}
+		return size
+	case ast.Union:
+		size := 0
+		for _, item := range node.Items {
+			size = saturate(size + expandedSize(item, alphabet))
+		}
+		return size
+	case ast.Star:
+		return expandedSize(node.Sub, alphabet)
+	case ast.Repeat:
+		copies := node.Max - node.Min
+		if node.Max < 0 {
+			copies = 1
+		}
+		return saturate(expandedSize(node.Sub, alphabet) * (node.Min + copies))
+	case ast.Class:
+		matched := len(node.Members)
+		if node.Negated {
+			matched = alphabet
+		}
+		return saturate(max(matched, 1))
+	}
+	return 1
+}
+
+func (p *parser) parseAtom() (ast.Node, error) {
+	current := p.peek()
+	switch current.kind {
+	case tokenLiteral:
+		p.next()
+		return ast.Literal{Symbol: current.symbol}, nil
+	case tokenClass:
+		p.next()
+		if !current.negated && len(current.members) == 0 {
+			return ast.Empty{}, nil
+		}
+		return ast.Class{Members: current.members, Negated: current.negated}, nil
+	case tokenEpsilon:
+		p.next()
+		return ast.Epsilon{}, nil
+	case tokenOpenParen:
+		p.next()
+		inner, err := p.parseUnion()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenCloseParen, `")"`); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+	return nil, &ParseError{Offset: current.offset, Expected: p.expectedAtom(), Found: current.describe()}
+}
+
+func (p *parser) expectedAtom() string {
+	if p.mode == ModeConventional {
+		return `literal, class or "("`
+	}
+	return `literal, class, "e" or "("`
+}
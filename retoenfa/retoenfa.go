@@ -1,204 +1,185 @@
 package retoenfa
 
 import (
-	"fmt"
 	. "github.com/jatin297/retoenfa/dto"
 	"github.com/jatin297/retoenfa/enfa"
-	"strconv"
+	"github.com/jatin297/retoenfa/regex/ast"
+	"github.com/jatin297/retoenfa/regex/simplify"
 )
 
 func NewReToeNFA(str string) *ReToeNFA {
-	newRe2NFA := &ReToeNFA{regexString: str}
-	newRe2NFA.closureMap = make(map[Closure]bool)
-	return newRe2NFA
+	return &ReToeNFA{regexString: str}
 }
 
 type ReToeNFA struct {
-	regexString     string
-	nextParentheses []int
-	stateCount      int
-	closureMap      map[Closure]bool
-	enfa            *enfa.ENFA
-}
-
-func (r *ReToeNFA) parseRE(expression string, start, end int) (int, int) {
-
-	// Base case: single character
-	if start == end {
-		initialState := r.incCapacity()
-		finalState := r.incCapacity()
-
-		// Handle epsilon transition or regular character
-		if expression[start] == 'e' {
-			r.addEdge(initialState, Epsilon, finalState)
-		} else {
-			symbol, _ := strconv.Atoi(string(expression[start]))
-			r.addEdge(initialState, symbol, finalState)
-		}
-		return initialState, finalState
-	}
+	regexString  string
+	mode         Mode
+	construction Construction
+	alphabet     []Symbol
+	simplify     bool
+	simplified   *ast.AST
+	steps        []simplify.Step
+	stateCount   int
+	enfa         *enfa.ENFA
+}
 
-	// Handle grouped expressions enclosed in parentheses
-	if expression[start] == '(' && expression[end] == ')' {
-		if r.nextParentheses[start] == end {
-			// Recursively parse the content inside parentheses
-			return r.parseRE(expression, start+1, end-1)
-		}
+func (r *ReToeNFA) incCapacity() int {
+	if r.enfa == nil {
+		r.enfa = enfa.CreateENFA(0, false)
+	} else {
+		r.enfa.InsertState(r.stateCount, false)
 	}
+	r.stateCount = r.stateCount + 1
+	return r.stateCount - 1
+}
 
-	// Check for union operator (+) and split into subexpressions
-	index := start
-	for index <= end {
-		index = r.nextParentheses[index] // Skip to the corresponding position if parentheses exist
+func (r *ReToeNFA) addEdge(stateSrc int, input Symbol, stateDst int) {
+	r.enfa.DefineTransition(stateSrc, input, stateDst)
+}
 
-		if index <= end && expression[index] == '+' {
-			leftStart, leftEnd := r.parseRE(expression, start, index-1)
-			rightStart, rightEnd := r.parseRE(expression, index+1, end)
-			unionStart, unionEnd := r.doUnion(leftStart, rightStart, leftEnd, rightEnd)
-			return unionStart, unionEnd
-		}
-		index++
-	}
+func (r *ReToeNFA) doUnion(s1, s2, t1, t2 int) (int, int) {
+	newStartState := r.incCapacity()
+	newFinalState := r.incCapacity()
 
-	// Check for concatenation operator (.) and process accordingly
-	index = start
-	for index <= end {
-		index = r.nextParentheses[index] // Skip nested parentheses
+	r.addEdge(newStartState, Epsilon, s1)
+	r.addEdge(newStartState, Epsilon, s2)
 
-		if index <= end && expression[index] == '.' {
-			leftStart, leftEnd := r.parseRE(expression, start, index-1)
-			rightStart, rightEnd := r.parseRE(expression, index+1, end)
-			concatStart, concatEnd := r.doConcatenation(leftStart, rightStart, leftEnd, rightEnd)
-			return concatStart, concatEnd
-		}
-		index++
-	}
+	r.addEdge(t1, Epsilon, newFinalState)
+	r.addEdge(t2, Epsilon, newFinalState)
 
-	subStart, subEnd := r.parseRE(expression, start, end-1)
-	closureStart, closureEnd := r.closure(subStart, subEnd)
-	return closureStart, closureEnd
+	return newStartState, newFinalState
 }
 
-func (r *ReToeNFA) computeParenthesesMapping(expression string) {
-	length := len(expression)
-	for index := 0; index < length; index++ {
-
-		// Identify the start of a parenthesis group
-		if expression[index] == '(' {
-			depth := 0
-			current := index
-
-			for {
-				if expression[current] == '(' {
-					depth++
-				}
+// SetMode selects the dialect the regular expression is written in, ModeClassic by default.
+func (r *ReToeNFA) SetMode(mode Mode) {
+	r.mode = mode
+}
 
-				if expression[current] == ')' {
-					depth--
-				}
+// SetAlphabet declares the input alphabet. Negated classes and wildcards match the alphabet
+// symbols they do not exclude; by default the alphabet is every character the expression names.
+func (r *ReToeNFA) SetAlphabet(alphabet []Symbol) {
+	r.alphabet = alphabet
+}
 
-				// Stop when matching closing parenthesis is found
-				if depth == 0 {
-					break
-				}
-				current++
-			}
+// SetSimplify makes StartParse rewrite the expression with simplify.Simplify before building.
+func (r *ReToeNFA) SetSimplify(enabled bool) {
+	r.simplify = enabled
+}
 
-			// Map the index to the corresponding closing parenthesis
-			r.nextParentheses = append(r.nextParentheses, current)
+// Simplified returns the expression the automaton was built from and the rewrites that led to
+// it, or nil when simplification is off.
+func (r *ReToeNFA) Simplified() (*ast.AST, []simplify.Step) {
+	return r.simplified, r.steps
+}
 
-		} else {
-			// Non-parenthesis characters map to themselves
-			r.nextParentheses = append(r.nextParentheses, index)
-		}
+// StartParse parses the regular expression and builds its eNFA with the selected construction. A malformed expression is
+// reported as a *ParseError and an automaton outgrowing its construction's limit as a *StateLimitError; neither leaves
+// an automaton behind.
+func (r *ReToeNFA) StartParse() error {
+	tree, err := Parse(r.regexString, r.mode)
+	if err != nil {
+		return err
 	}
-}
 
-func (r *ReToeNFA) computeStateClosure() {
-	// Initialize a temporary queue to process states
-	stateQueue := make([]int, 200)
+	if r.alphabet == nil {
+		r.alphabet = ast.Symbols(tree.Root)
+	}
+	if expandedSize(tree.Root, len(r.alphabet)) > maxExpandedSize {
+		return &StateLimitError{Construction: "expression", Limit: maxExpandedSize, Unit: "symbols"}
+	}
 
-	// Iterate through all states to calculate closures
-	for srcState := 0; srcState <= r.stateCount; srcState++ {
+	// The alphabet is taken before simplifying, which may drop symbols from the expression
+	if r.simplify {
+		tree.Root, r.steps = simplify.Simplify(tree.Root)
+		r.simplified = tree
+	}
 
-		// Assume all states are reachable initially
-		for targetState := 0; targetState < r.stateCount; targetState++ {
-			r.closureMap[Closure{Src: srcState, Dst: targetState}] = true
+	switch r.construction {
+	case ConstructionGlushkov:
+		if err := r.buildGlushkov(tree.Root); err != nil {
+			r.enfa, r.stateCount = nil, 0
+			return err
 		}
-
-		// Setup for breadth-first traversal
-		queueStart := -1
-		queueEnd := 0
-		stateQueue[0] = srcState
-
-		// Each state includes itself in its closure
-		r.closureMap[Closure{Src: srcState, Dst: srcState}] = true
-
-		// Process the queue for reachable states
-		for queueStart < queueEnd {
-			queueStart++
-			currentState := stateQueue[queueStart]
-
-			// Explore all possible transitions
-			for potentialState := 0; potentialState < r.stateCount; potentialState++ {
-				closureAlreadyPresent := r.checkClosureExist(srcState, potentialState)
-				validEpsilonPath := r.checkPathExist(currentState, Epsilon, potentialState)
-
-				// Add the state to the closure if it's reachable and not already present
-				if !closureAlreadyPresent && validEpsilonPath {
-					queueEnd++
-					stateQueue[queueEnd] = potentialState
-
-					r.closureMap[Closure{Src: srcState, Dst: potentialState}] = true
-				}
-			}
+	case ConstructionBrzozowski, ConstructionAntimirov:
+		successors := derivatives{alphabet: r.alphabet}.brzozowski
+		if r.construction == ConstructionAntimirov {
+			successors = derivatives{alphabet: r.alphabet}.antimirov
+		}
+		if err := r.buildDerivatives(tree.Root, successors); err != nil {
+			r.enfa, r.stateCount = nil, 0
+			return err
 		}
+	default:
+		nfaStart, nfaFinal := r.build(tree.Root)
+		r.enfa.SetInitialState(nfaStart)
+		r.enfa.MarkFinalState(nfaFinal)
 	}
-}
-
-func (r *ReToeNFA) incCapacity() int {
-	if r.enfa == nil {
-		r.enfa = enfa.CreateENFA(0, false)
-	} else {
-		r.enfa.InsertState(r.stateCount-1, false)
+	for _, symbol := range r.alphabet {
+		r.enfa.AddInputSymbol(symbol)
 	}
-	r.stateCount = r.stateCount + 1
-	return r.stateCount - 1
+	return nil
 }
 
-func (r *ReToeNFA) addEdge(stateSrc int, cInput int, stateDst int) {
-	var inputString string
-	if cInput != 2 {
-		inputString = strconv.Itoa(cInput)
-	}
-	r.enfa.DefineTransition(stateSrc, inputString, stateDst)
+func (r *ReToeNFA) GetEpsNFA() *enfa.ENFA {
+	return r.enfa
 }
 
-func (r *ReToeNFA) doUnion(s1, s2, t1, t2 int) (int, int) {
-	newStartState := r.incCapacity()
-	newFinalState := r.incCapacity()
-
-	r.addEdge(newStartState, 2, s1)
-	r.addEdge(newStartState, 2, s2)
+// Equivalent reports whether two regular expressions denote the same language. When they differ,
+// witness is the shortest string accepted by exactly one of them and acceptedByFirst tells which.
+// Negated classes and wildcards range over every character named by either expression.
+func Equivalent(first, second string, mode Mode) (equivalent bool, witness []Symbol, acceptedByFirst bool, err error) {
+	alphabet, err := sharedAlphabet(mode, first, second)
+	if err != nil {
+		return false, nil, false, err
+	}
 
-	r.addEdge(t1, 2, newFinalState)
-	r.addEdge(t2, 2, newFinalState)
+	firstENFA, err := compile(first, mode, alphabet)
+	if err != nil {
+		return false, nil, false, err
+	}
+	secondENFA, err := compile(second, mode, alphabet)
+	if err != nil {
+		return false, nil, false, err
+	}
+	return enfa.Equivalent(firstENFA, secondENFA)
+}
 
-	return newStartState, newFinalState
+// sharedAlphabet collects the characters named by any of the expressions.
+func sharedAlphabet(mode Mode, expressions ...string) ([]Symbol, error) {
+	seen := make(map[Symbol]bool)
+	alphabet := []Symbol{}
+	for _, expression := range expressions {
+		tree, err := Parse(expression, mode)
+		if err != nil {
+			return nil, err
+		}
+		for _, symbol := range ast.Symbols(tree.Root) {
+			if !seen[symbol] {
+				seen[symbol] = true
+				alphabet = append(alphabet, symbol)
+			}
+		}
+	}
+	return alphabet, nil
 }
 
-func (r *ReToeNFA) StartParse() {
-	r.computeParenthesesMapping(r.regexString)
-	nfaStart, nfaFinal := r.parseRE(r.regexString, 0, len(r.regexString)-1)
-	fmt.Printf("NFA s=%d, f=%d\n", nfaStart, nfaFinal)
+func compile(expression string, mode Mode, alphabet []Symbol) (*enfa.ENFA, error) {
+	trans := NewReToeNFA(expression)
+	trans.SetMode(mode)
+	trans.SetAlphabet(alphabet)
+	if err := trans.StartParse(); err != nil {
+		return nil, err
+	}
+	return trans.GetEpsNFA(), nil
 }
 
-func (r *ReToeNFA) GetEpsNFA() *enfa.ENFA {
-	return r.enfa
+// GetNFA returns the parsed automaton with its epsilon transitions eliminated.
+func (r *ReToeNFA) GetNFA() (*enfa.ENFA, error) {
+	return r.enfa.RemoveEpsilons()
 }
 
 func (r *ReToeNFA) doConcatenation(s1, s2, t1, t2 int) (int, int) {
-	r.addEdge(t1, 2, s2)
+	r.addEdge(t1, Epsilon, s2)
 	return s1, t2
 }
 
@@ -206,22 +187,9 @@ func (r *ReToeNFA) closure(s, t int) (int, int) {
 	newStartState := r.incCapacity()
 	newFinalState := r.incCapacity()
 
-	r.addEdge(newStartState, 2, s)
-	r.addEdge(t, 2, newFinalState)
-	r.addEdge(t, 2, s)
-	r.addEdge(newStartState, 2, newFinalState)
+	r.addEdge(newStartState, Epsilon, s)
+	r.addEdge(t, Epsilon, newFinalState)
+	r.addEdge(t, Epsilon, s)
+	r.addEdge(newStartState, Epsilon, newFinalState)
 	return newStartState, newFinalState
 }
-
-func (r *ReToeNFA) checkClosureExist(src, target int) bool {
-	closureExist, _ := r.closureMap[Closure{Src: src, Dst: target}]
-	return closureExist
-}
-
-func (r *ReToeNFA) checkPathExist(src, input, dst int) bool {
-	if r.enfa == nil {
-		return false
-	}
-
-	return r.enfa.IsPathExists(src, strconv.Itoa(input), dst)
-}
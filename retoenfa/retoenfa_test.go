@@ -1,6 +1,17 @@
 package retoenfa
 
-import "testing"
+import (
+	"errors"
+	"github.com/jatin297/retoenfa/dfa"
+	. "github.com/jatin297/retoenfa/dto"
+	"github.com/jatin297/retoenfa/enfa"
+	"github.com/jatin297/retoenfa/regex/ast"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
 
 func TestBasicRegex(t *testing.T) {
 	trans := NewReToeNFA("1.0.1")
@@ -22,3 +33,659 @@ func TestComplexRegex(t *testing.T) {
 	enfa := trans.GetEpsNFA()
 	enfa.GenerateFormattedTransitionTable()
 }
+
+// toGoRegexp rewrites an expression of this dialect into an anchored Go regular expression.
+func toGoRegexp(expression string) *regexp.Regexp {
+	var builder strings.Builder
+	for _, char := range expression {
+		switch char {
+		case '+':
+			builder.WriteString("|")
+		case '.':
+		case 'e':
+			builder.WriteString("(?:)")
+		default:
+			builder.WriteRune(char)
+		}
+	}
+	return regexp.MustCompile("^(?:" + builder.String() + ")$")
+}
+
+// randomRegex generates a fully parenthesized expression over the literals 0, 1 and e.
+func randomRegex(rng *rand.Rand, depth int) string {
+	if depth == 0 {
+		return []string{"0", "1", "e"}[rng.Intn(3)]
+	}
+	switch rng.Intn(4) {
+	case 0:
+		return "(" + randomRegex(rng, depth-1) + "+" + randomRegex(rng, depth-1) + ")"
+	case 1:
+		return "(" + randomRegex(rng, depth-1) + "." + randomRegex(rng, depth-1) + ")"
+	case 2:
+		return "(" + randomRegex(rng, depth-1) + ")*"
+	default:
+		return randomRegex(rng, 0)
+	}
+}
+
+// allStrings returns every string over the alphabet up to the given length.
+func allStrings(alphabet string, maxLength int) []string {
+	inputs := []string{""}
+	for index := 0; index < len(inputs); index++ {
+		if len(inputs[index]) < maxLength {
+			for _, char := range alphabet {
+				inputs = append(inputs, inputs[index]+string(char))
+			}
+		}
+	}
+	return inputs
+}
+
+func assertMatchesGoRegexp(t *testing.T, expression string) {
+	t.Helper()
+	assertAgreesWith(t, expression, ModeClassic, toGoRegexp(expression))
+}
+
+func assertAgreesWith(t *testing.T, expression string, mode Mode, expected *regexp.Regexp) {
+	t.Helper()
+	assertAgreesOver(t, expression, mode, "01", 6, expected)
+}
+
+func assertAgreesOver(t *testing.T, expression string, mode Mode, alphabet string, maxLength int, expected *regexp.Regexp) {
+	t.Helper()
+	trans := NewReToeNFA(expression)
+	trans.SetMode(mode)
+	trans.SetAlphabet(SplitSymbols(alphabet))
+	if err := trans.StartParse(); err != nil {
+		t.Fatalf("%s: unexpected parse error: %s", expression, err)
+	}
+	eNFA := trans.GetEpsNFA().Compile()
+	automaton, err := dfa.FromENFA(trans.GetEpsNFA())
+	if err != nil {
+		t.Fatalf("%s: %v", expression, err)
+	}
+
+	for _, input := range allStrings(alphabet, maxLength) {
+		symbols := SplitSymbols(input)
+		if got, want := automaton.ValidateInputSequence(symbols), expected.MatchString(input); got != want {
+			t.Errorf("%s on %q: expect accepted=%t, but get %t", expression, input, want, got)
+		}
+
+		if got, want := eNFA.Match(symbols), expected.MatchString(input); got != want {
+			t.Errorf("%s on %q: expect eNFA accepted=%t, but get %t", expression, input, want, got)
+		}
+	}
+}
+
+func TestRegexAgainstGoRegexp(t *testing.T) {
+	expressions := []string{
+		"1.0.1",
+		"0+1.0.1",
+		"(0+1.0)*.(e+1)",
+		"0*",
+		"(0.1)*",
+		"(0+1)*.1",
+		"1.0*",
+		"0+1*",
+		"(0*.1*)*",
+		"(e+1).0",
+		"e",
+	}
+	for _, expression := range expressions {
+		assertMatchesGoRegexp(t, expression)
+	}
+}
+
+func TestGeneratedRegexAgainstGoRegexp(t *testing.T) {
+	rng := rand.New(rand.NewSource(297))
+	for iteration := 0; iteration < 200; iteration++ {
+		assertMatchesGoRegexp(t, randomRegex(rng, 4))
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	if equivalent, witness, _, _ := Equivalent("(0+1)*", "(0*.1*)*", ModeClassic); !equivalent {
+		t.Errorf("Expect (0+1)* and (0*.1*)* to be equivalent, but get witness %v", witness)
+	}
+
+	equivalent, witness, acceptedByFirst, err := Equivalent("0*", "0.0*", ModeClassic)
+	if err != nil || equivalent || len(witness) != 0 || !acceptedByFirst {
+		t.Errorf("Expect the empty string accepted by 0* only, but get %v (first=%t)", witness, acceptedByFirst)
+	}
+
+	// Regex pairs that differ, with the shortest input telling them apart
+	cases := []struct {
+		first, second   string
+		witness         string
+		acceptedByFirst bool
+	}{
+		{"(a|b)*abb", "(a|b)*ab", "ab", false},
+		{"a(b|c)", "ab|ac|ad", "ad", false},
+		{"(ab)*", "a*b*", "a", false},
+		{"a{2,4}", "aa|aaa", "aaaa", true},
+	}
+	for _, c := range cases {
+		equivalent, witness, acceptedByFirst, err := Equivalent(c.first, c.second, ModeConventional)
+		if err != nil || equivalent || JoinSymbols(witness) != c.witness || acceptedByFirst != c.acceptedByFirst {
+			t.Errorf("%s vs %s: expect witness %q (first=%t), but get %q (first=%t, err=%v)", c.first, c.second, c.witness, c.acceptedByFirst, JoinSymbols(witness), acceptedByFirst, err)
+		}
+	}
+
+	// Determinizing (a|b)*a(a|b){14} on the fly needs 2^15 pairs of state sets
+	var limitErr *StateLimitError
+	if _, _, _, err := Equivalent("(a|b)*a(a|b){14}", "(b|a)*a(b|a){14}", ModeConventional); !errors.As(err, &limitErr) {
+		t.Errorf("Expect a StateLimitError, but get %v", err)
+	}
+}
+
+func accepts(expression, input string) bool {
+	trans := NewReToeNFA(expression)
+	trans.StartParse()
+	return trans.GetEpsNFA().ValidateInputSequence(SplitSymbols(input))
+}
+
+func TestSymbolModel(t *testing.T) {
+	cases := []struct {
+		expression string
+		input      string
+		accepted   bool
+	}{
+		{"1.2", "12", true},
+		{"1.2", "1", false},
+		{"2*", "222", true},
+		{"a.b", "ab", true},
+		{"a.e", "a", true},
+		{"a.e", "ae", false},
+		{"a.\\e", "ae", true},
+		{"a.\\e", "a", false},
+		{"(\\e+x)*", "exe", true},
+		{"α.β", "αβ", true},
+		{"日*", "日日日", true},
+		{"(a+b+c)*.\\+", "ab+", true},
+		{"(a+b+c)*.\\+", "ab", false},
+		{"\\(.x.\\)", "(x)", true},
+		{"\\..\\*", ".*", true},
+		{"(\\.+\\*)*", "*.*", true},
+		{"\\\\", "\\", true},
+		{"(é+ü).\\é", "üé", true},
+	}
+	for _, c := range cases {
+		if got := accepts(c.expression, c.input); got != c.accepted {
+			t.Errorf("%s on %q: expect accepted=%t, but get %t", c.expression, c.input, c.accepted, got)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		expression string
+		offset     int
+		expected   string
+	}{
+		{"", 0, `literal, class, "e" or "("`},
+		{"(0+1", 4, `")"`},
+		{"((0)", 4, `")"`},
+		{"0+1)", 3, "operator or end of input"},
+		{"01", 1, "operator or end of input"},
+		{"0+", 2, `literal, class, "e" or "("`},
+		{"*0", 0, `literal, class, "e" or "("`},
+		{"0.()", 3, `literal, class, "e" or "("`},
+		{"0\\", 2, "character after escape"},
+	}
+	for _, c := range cases {
+		err := NewReToeNFA(c.expression).StartParse()
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%q: expect a parse error, but get %v", c.expression, err)
+			continue
+		}
+		if parseErr.Offset != c.offset || parseErr.Expected != c.expected {
+			t.Errorf("%q: expect offset %d expecting %s, but get %s", c.expression, c.offset, c.expected, parseErr)
+		}
+	}
+
+	if _, _, _, err := Equivalent("0", "(1", ModeClassic); err == nil {
+		t.Errorf("Expect Equivalent to report the malformed expression")
+	}
+}
+
+// randomConventionalRegex generates an expression over 0 and 1 in the conventional dialect.
+func randomConventionalRegex(rng *rand.Rand, depth int) string {
+	if depth == 0 {
+		return []string{"0", "1"}[rng.Intn(2)]
+	}
+	switch rng.Intn(5) {
+	case 0:
+		return "(" + randomConventionalRegex(rng, depth-1) + "|" + randomConventionalRegex(rng, depth-1) + ")"
+	case 1:
+		return randomConventionalRegex(rng, depth-1) + randomConventionalRegex(rng, depth-1)
+	case 2:
+		return "(" + randomConventionalRegex(rng, depth-1) + ")" + []string{"*", "+", "?"}[rng.Intn(3)]
+	case 3:
+		return "(" + randomConventionalRegex(rng, depth-1) + ")" + []string{"{2}", "{1,}", "{0,2}", "{1,3}"}[rng.Intn(4)]
+	default:
+		return randomConventionalRegex(rng, 0)
+	}
+}
+
+func TestConventionalAgainstGoRegexp(t *testing.T) {
+	expressions := []string{
+		"10*1",
+		"(0|1)*1",
+		"0+1?",
+		"(01)+",
+		"1{3}",
+		"0{2,}",
+		"(0|1){1,2}0",
+		"(|1)0",
+		"0|",
+		"()",
+	}
+	for _, expression := range expressions {
+		assertAgreesWith(t, expression, ModeConventional, regexp.MustCompile("^(?:"+expression+")$"))
+	}
+
+	rng := rand.New(rand.NewSource(11))
+	for iteration := 0; iteration < 200; iteration++ {
+		expression := randomConventionalRegex(rng, 4)
+		assertAgreesWith(t, expression, ModeConventional, regexp.MustCompile("^(?:"+expression+")$"))
+	}
+}
+
+func TestConventionalParseErrors(t *testing.T) {
+	cases := []struct {
+		expression string
+		offset     int
+		expected   string
+	}{
+		{"*0", 0, `literal, class or "("`},
+		{"0|+", 2, `literal, class or "("`},
+		{"0{2", 3, `"}"`},
+		{"0{3,1}", 1, "repetition {m}, {m,} or {m,n}"},
+		{"0{x}", 1, "repetition {m}, {m,} or {m,n}"},
+		{"(01", 3, `")"`},
+		// Nested and sibling repetitions count towards one limit
+		{"(a{1000}){1000}", 9, "at most 10000 symbols with repetitions expanded"},
+		{"a{10}{1000}{2}", 11, "at most 10000 symbols with repetitions expanded"},
+		{"b(a{100}){100}", 1, "at most 10000 symbols with repetitions expanded"},
+		// A class counts once per member
+		{"([ -П]{60}){60}", 7, "at most 10000 symbols with repetitions expanded"},
+	}
+	for _, c := range cases {
+		trans := NewReToeNFA(c.expression)
+		trans.SetMode(ModeConventional)
+		err := trans.StartParse()
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%q: expect a parse error, but get %v", c.expression, err)
+			continue
+		}
+		if parseErr.Offset != c.offset || parseErr.Expected != c.expected {
+			t.Errorf("%q: expect offset %d expecting %s, but get %s", c.expression, c.offset, c.expected, parseErr)
+		}
+	}
+
+	if _, err := Parse("(a{100}){100}", ModeConventional); err != nil {
+		t.Errorf("Expect exactly %d expanded symbols to parse, but get %v", maxExpandedSize, err)
+	}
+
+	// A negated class counts once per alphabet symbol, which is only known when building
+	negated := NewReToeNFA("([^a]{20}){20}")
+	negated.SetMode(ModeConventional)
+	negated.SetAlphabet(SplitSymbols("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789+/-_!?"))
+	var limitErr *StateLimitError
+	if err := negated.StartParse(); !errors.As(err, &limitErr) || limitErr.Limit != maxExpandedSize {
+		t.Errorf("Expect a StateLimitError, but get %v", err)
+	}
+
+	if equivalent, witness, _, err := Equivalent("a+", "aa*", ModeConventional); err != nil || !equivalent {
+		t.Errorf("Expect a+ and aa* to be equivalent, but get witness %v (err=%v)", witness, err)
+	}
+}
+
+func TestCharacterClasses(t *testing.T) {
+	conventional := []string{
+		"[01]*2",
+		"[^0]+",
+		"[0-1]{2}",
+		".*2.",
+		"[^0-1]|0",
+		"[2-2][-0]",
+		"[\\]0]",
+	}
+	for _, expression := range conventional {
+		assertAgreesOver(t, expression, ModeConventional, "012-]", 3, regexp.MustCompile("^(?:"+expression+")$"))
+	}
+
+	classic := map[string]string{
+		"[0-1]*.2":  "[0-1]*2",
+		"[^0].[^]":  "[^0].",
+		"([^]+e).0": "(?:.|)0",
+	}
+	for expression, goExpression := range classic {
+		assertAgreesOver(t, expression, ModeClassic, "012", 4, regexp.MustCompile("^(?:"+goExpression+")$"))
+	}
+
+	// Without a declared alphabet a negated class ranges over the characters the expression names
+	if !accepts("[^a].b", "bb") || accepts("[^a].b", "cb") {
+		t.Errorf("Expect [^a] to match b but not c over the default alphabet")
+	}
+
+	tree, err := Parse("[a-c]x", ModeConventional)
+	if err != nil || len(ast.Symbols(tree.Root)) != 4 {
+		t.Errorf("Expect four named symbols, but get %v (err=%v)", tree, err)
+	}
+
+	for expression, offset := range map[string]int{"[ab": 3, "[z-a]": 1, "[\u0000-\uffff]": 1} {
+		_, err := Parse(expression, ModeConventional)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || parseErr.Offset != offset {
+			t.Errorf("%q: expect a parse error at offset %d, but get %v", expression, offset, err)
+		}
+	}
+}
+
+func TestPrintRoundTrip(t *testing.T) {
+	expressions := map[Mode][]string{
+		ModeClassic:      {"0", "e", "[]", "0.1.0", "(0.1).0", "0+1+e", "(0+1)*.0", "0**", "[^0].[a-c]", `\+.\e`, "[^]*"},
+		ModeConventional: {"0", "()", "[]", "010", "(01)0", "0|1|", "(0|1)*0", "0+?", "a{2,3}b{4}c{3,}", ".[^0]", `\|\.\{`, "[a-z]x?"},
+	}
+	for mode, list := range expressions {
+		for _, expression := range list {
+			tree, err := Parse(expression, mode)
+			if err != nil {
+				t.Fatalf("%q: %v", expression, err)
+			}
+			printed := tree.String()
+			if printed != expression {
+				t.Errorf("%q: expect to print back unchanged, but get %q", expression, printed)
+			}
+			reparsed, err := Parse(printed, mode)
+			if err != nil || !reflect.DeepEqual(reparsed, tree) {
+				t.Errorf("%q: expect %q to parse to the same tree (err=%v)", expression, printed, err)
+			}
+		}
+	}
+
+	// Random expressions survive a round trip through either dialect
+	random := rand.New(rand.NewSource(13))
+	for i := 0; i < 300; i++ {
+		expression := randomConventionalRegex(random, 4)
+		tree, err := Parse(expression, ModeConventional)
+		if err != nil {
+			t.Fatalf("%q: %v", expression, err)
+		}
+		reparsed, err := Parse(tree.String(), ModeConventional)
+		if err != nil || !reflect.DeepEqual(reparsed, tree) {
+			t.Errorf("%q: printed as %q which parses differently (err=%v)", expression, tree.String(), err)
+		}
+		classic := ast.Print(tree.Root, ModeClassic)
+		assertAgreesOver(t, classic, ModeClassic, "01", 5, regexp.MustCompile("^(?:"+expression+")$"))
+	}
+}
+
+func TestGlushkovConstruction(t *testing.T) {
+	random := rand.New(rand.NewSource(14))
+	expressions := []string{"(0|1)*1", "0{2,3}", "()", "(01|1)*0?", "[^1]1|."}
+	for i := 0; i < 200; i++ {
+		expressions = append(expressions, randomConventionalRegex(random, 4))
+	}
+
+	for _, expression := range expressions {
+		trans := NewReToeNFA(expression)
+		trans.SetMode(ModeConventional)
+		trans.SetConstruction(ConstructionGlushkov)
+		trans.SetAlphabet(SplitSymbols("01"))
+		if err := trans.StartParse(); err != nil {
+			t.Fatalf("%s: unexpected parse error: %s", expression, err)
+		}
+		eNFA := trans.GetEpsNFA()
+
+		for _, state := range eNFA.States() {
+			if next := eNFA.NextStates(state, Epsilon); len(next) != 0 {
+				t.Errorf("%s: expect no epsilon edges, but %d has %v", expression, state, next)
+			}
+		}
+
+		expected := regexp.MustCompile("^(?:" + expression + ")$")
+		automaton, err := dfa.FromENFA(eNFA)
+		if err != nil {
+			t.Fatalf("%s: %v", expression, err)
+		}
+		for _, input := range allStrings("01", 5) {
+			symbols := SplitSymbols(input)
+			if got, want := automaton.ValidateInputSequence(symbols), expected.MatchString(input); got != want {
+				t.Errorf("%s on %q: expect accepted=%t, but get %t", expression, input, want, got)
+			}
+		}
+	}
+
+	// One state per literal occurrence plus the initial state
+	for expression, states := range map[string]int{"(a+b)*.a.b.b": 6, "a.b+e": 3, "e": 1, "[ab].c*": 3} {
+		trans := NewReToeNFA(expression)
+		trans.SetConstruction(ConstructionGlushkov)
+		if err := trans.StartParse(); err != nil {
+			t.Fatal(err)
+		}
+		if got := len(trans.GetEpsNFA().States()); got != states {
+			t.Errorf("%s: expect %d states, but get %d", expression, states, got)
+		}
+	}
+
+	// Every position of ((a*){70}){70} follows every other one
+	positions := NewReToeNFA("((a*){70}){70}")
+	positions.SetMode(ModeConventional)
+	positions.SetConstruction(ConstructionGlushkov)
+	var limitErr *StateLimitError
+	if err := positions.StartParse(); !errors.As(err, &limitErr) || limitErr.Limit != maxGlushkovTransitions {
+		t.Errorf("Expect a StateLimitError, but get %v", err)
+	}
+	if positions.GetEpsNFA() != nil {
+		t.Errorf("Expect no automaton to be left behind")
+	}
+
+	if _, err := ParseConstruction("mcnaughton"); err == nil {
+		t.Errorf("Expect an unknown construction to be rejected")
+	}
+}
+
+func TestDerivativeConstructions(t *testing.T) {
+	random := rand.New(rand.NewSource(15))
+	expressions := []string{"(0|1)*1", "0{2,3}", "()", "[]", "0[]|1", "(01|1)*0?", "[^1]1|.", "((0|1)*)*", "(0?){3}1"}
+	for i := 0; i < 200; i++ {
+		expressions = append(expressions, randomConventionalRegex(random, 4))
+	}
+
+	for _, construction := range []Construction{ConstructionBrzozowski, ConstructionAntimirov} {
+		for _, expression := range expressions {
+			trans := NewReToeNFA(expression)
+			trans.SetMode(ModeConventional)
+			trans.SetConstruction(construction)
+			trans.SetAlphabet(SplitSymbols("01"))
+			if err := trans.StartParse(); err != nil {
+				t.Fatalf("%s: unexpected error: %s", expression, err)
+			}
+			eNFA := trans.GetEpsNFA()
+
+			for _, state := range eNFA.States() {
+				if next := eNFA.NextStates(state, Epsilon); len(next) != 0 {
+					t.Errorf("%s: expect no epsilon edges, but %d has %v", expression, state, next)
+				}
+				for _, symbol := range SplitSymbols("01") {
+					if next := eNFA.NextStates(state, symbol); construction == ConstructionBrzozowski && len(next) > 1 {
+						t.Errorf("%s: expect a deterministic automaton, but %d on %s goes to %v", expression, state, symbol, next)
+					}
+				}
+			}
+
+			expected := regexp.MustCompile("^(?:" + strings.ReplaceAll(expression, "[]", "[^\\x00-\\x{10FFFF}]") + ")$")
+			for _, input := range allStrings("01", 5) {
+				if got, want := eNFA.ValidateInputSequence(SplitSymbols(input)), expected.MatchString(input); got != want {
+					t.Errorf("%s (construction %d) on %q: expect accepted=%t, but get %t", expression, construction, input, want, got)
+				}
+			}
+		}
+	}
+
+	// The Brzozowski DFA of (a|b)*a(a|b){12} needs 2^13 states, past the limit
+	blowup := NewReToeNFA("(a|b)*a(a|b){12}")
+	blowup.SetMode(ModeConventional)
+	blowup.SetConstruction(ConstructionBrzozowski)
+	var limitErr *StateLimitError
+	if err := blowup.StartParse(); !errors.As(err, &limitErr) || limitErr.Limit != maxDerivativeStates {
+		t.Errorf("Expect a StateLimitError, but get %v", err)
+	}
+	if blowup.GetEpsNFA() != nil {
+		t.Errorf("Expect no automaton to be left behind")
+	}
+
+	// Similar derivatives share a state: (a|b)*abb gives the textbook four-state DFA
+	trans := NewReToeNFA("(a|b)*abb")
+	trans.SetMode(ModeConventional)
+	trans.SetConstruction(ConstructionBrzozowski)
+	if err := trans.StartParse(); err != nil {
+		t.Fatal(err)
+	}
+	if states := len(trans.GetEpsNFA().States()); states != 4 {
+		t.Errorf("Expect 4 states, but get %d", states)
+	}
+
+	// Partial derivatives of a.b*: the expression itself, b* and nothing else
+	trans = NewReToeNFA("a.b*")
+	trans.SetConstruction(ConstructionAntimirov)
+	if err := trans.StartParse(); err != nil {
+		t.Fatal(err)
+	}
+	if states := len(trans.GetEpsNFA().States()); states != 2 {
+		t.Errorf("Expect 2 states, but get %d", states)
+	}
+}
+
+func TestToRegex(t *testing.T) {
+	rng := rand.New(rand.NewSource(16))
+	expressions := []string{"0", "e", "[]", "(0+1)*.1", "0.(1.0)*.1+1", "(0*.1*)*"}
+	for i := 0; i < 150; i++ {
+		expressions = append(expressions, randomRegex(rng, 4))
+	}
+
+	alphabet := SplitSymbols("01")
+	for _, expression := range expressions {
+		original, err := compile(expression, ModeClassic, alphabet)
+		if err != nil {
+			t.Fatalf("%s: %v", expression, err)
+		}
+		for _, order := range []EliminationOrder{EliminateInOrder, EliminateMinDegree, EliminateMinWeight} {
+			regex, err := ToRegex(original, order)
+			if err != nil {
+				t.Fatalf("%s: %v", expression, err)
+			}
+			printed := ast.Print(regex, ModeClassic)
+			roundTrip, err := compile(printed, ModeClassic, alphabet)
+			if err != nil {
+				t.Fatalf("%s: ToRegex printed %q which does not parse: %v", expression, printed, err)
+			}
+			if equivalent, witness, _, _ := enfa.Equivalent(original, roundTrip); !equivalent {
+				t.Errorf("%s: order %d gives %q, which differs on %v", expression, order, printed, witness)
+			}
+		}
+	}
+
+	// The transition table carries enough to come back, also from a complete DFA
+	trans := NewReToeNFA("(a+b)*.a.b.b")
+	if err := trans.StartParse(); err != nil {
+		t.Fatal(err)
+	}
+	complete, err := dfa.FromENFA(trans.GetEpsNFA())
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := enfa.FromTransitionTable(complete.GenerateFormattedTransitionTable())
+	if err != nil {
+		t.Fatal(err)
+	}
+	regex, err := ToRegex(loaded, EliminateMinWeight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	printed := ast.Print(regex, ModeConventional)
+	if equivalent, _, _, _ := Equivalent("(a|b)*abb", printed, ModeConventional); !equivalent {
+		t.Errorf("Expect (a|b)*abb back, but get %q", printed)
+	}
+	if len(printed) > 40 {
+		t.Errorf("Expect a simplified expression, but get %q", printed)
+	}
+
+	// Negative state numbers must not collide with the states ToRegex adds
+	negative, err := enfa.FromTransitionTable(Table{
+		InitialState: -2,
+		Symbols:      []string{"a"},
+		Rows: []TableRow{
+			{State: -2, Next: [][]int{{-3}}},
+			{State: -3, Final: true, Next: [][]int{{}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if regex, err := ToRegex(negative, EliminateMinWeight); err != nil || ast.Print(regex, ModeClassic) != "a" {
+		t.Errorf("Expect a from states -2 and -3, but get %v, %v", regex, err)
+	}
+}
+
+func TestToRegexLimit(t *testing.T) {
+	// Eliminating the states of a random automaton multiplies the labels each time
+	rng := rand.New(rand.NewSource(18))
+	random := enfa.CreateENFA(0, false)
+	for state := 1; state < 200; state++ {
+		random.InsertState(state, rng.Intn(3) == 0)
+	}
+	for state := 0; state < 200; state++ {
+		random.DefineTransition(state, "a", rng.Intn(200))
+		random.DefineTransition(state, "b", rng.Intn(200))
+	}
+
+	var limitErr *StateLimitError
+	if _, err := ToRegex(random, EliminateMinWeight); !errors.As(err, &limitErr) || limitErr.Limit != maxLabelSymbols {
+		t.Errorf("Expect a StateLimitError, but get %v", err)
+	}
+}
+
+func TestSimplifyBeforeBuilding(t *testing.T) {
+	rng := rand.New(rand.NewSource(17))
+	alphabet := SplitSymbols("01")
+	for i := 0; i < 200; i++ {
+		expression := randomRegex(rng, 5)
+		original, err := compile(expression, ModeClassic, alphabet)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		trans := NewReToeNFA(expression)
+		trans.SetAlphabet(alphabet)
+		trans.SetSimplify(true)
+		if err := trans.StartParse(); err != nil {
+			t.Fatal(err)
+		}
+		if equivalent, witness, _, _ := enfa.Equivalent(original, trans.GetEpsNFA()); !equivalent {
+			simplified, _ := trans.Simplified()
+			t.Errorf("%s: simplified to %s, which differs on %v", expression, simplified, witness)
+		}
+		if len(trans.GetEpsNFA().States()) > len(original.States()) {
+			t.Errorf("%s: expect simplification not to grow the automaton", expression)
+		}
+	}
+
+	// Negated classes keep the alphabet of the expression as written
+	trans := NewReToeNFA("[^a].b+[].c")
+	trans.SetSimplify(true)
+	if err := trans.StartParse(); err != nil {
+		t.Fatal(err)
+	}
+	simplified, steps := trans.Simplified()
+	if simplified.String() != "[^a].b" || len(steps) == 0 {
+		t.Errorf("Expect [^a].b, but get %s after %v", simplified, steps)
+	}
+	if !trans.GetEpsNFA().ValidateInputSequence(SplitSymbols("cb")) {
+		t.Errorf("Expect c to stay in the alphabet after [].c is dropped")
+	}
+}